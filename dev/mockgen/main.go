@@ -0,0 +1,292 @@
+// Command mockgen generates the fakes of the service Client interfaces in service/mocks.
+//
+// Every method of the interfaces must have the form
+//
+//	Method(context.Context, *Input) (*pangea.PangeaResponse[Output], error)
+//
+// Usage, from service/mocks:
+//
+//	go run ../../dev/mockgen -services .. -out .
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const modulePath = "github.com/pangeacyber/go-pangea"
+
+type service struct {
+	Package string
+	Type    string
+	Methods []*method
+}
+
+type method struct {
+	Name   string
+	Input  string
+	Output string
+}
+
+func main() {
+	servicesDir := flag.String("services", "service", "directory of the service packages")
+	outDir := flag.String("out", "service/mocks", "directory the fakes are written to")
+	flag.Parse()
+
+	services, err := parseServices(*servicesDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, svc := range services {
+		err := writeService(*outDir, svc)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// parseServices returns the services with a Client interface, sorted by package.
+func parseServices(dir string) ([]*service, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var services []*service
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		svc, err := parseService(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if svc != nil {
+			services = append(services, svc)
+		}
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Package < services[j].Package
+	})
+	return services, nil
+}
+
+func parseService(dir string) (*service, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for name, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			iface := findInterface(file, "Client")
+			if iface == nil {
+				continue
+			}
+			svc := &service{
+				Package: name,
+				Type:    typeName(name),
+			}
+			for _, field := range iface.Methods.List {
+				m, err := parseMethod(name, field)
+				if err != nil {
+					return nil, fmt.Errorf("mockgen: %v.Client: %w", name, err)
+				}
+				svc.Methods = append(svc.Methods, m)
+			}
+			return svc, nil
+		}
+	}
+	return nil, nil
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if iface, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name == name {
+				return iface
+			}
+		}
+	}
+	return nil
+}
+
+func parseMethod(pkg string, field *ast.Field) (*method, error) {
+	if len(field.Names) != 1 {
+		return nil, fmt.Errorf("embedded interfaces are not supported")
+	}
+	name := field.Names[0].Name
+	fn, ok := field.Type.(*ast.FuncType)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a method", name)
+	}
+
+	params := flattenFields(fn.Params)
+	results := flattenFields(fn.Results)
+	if len(params) != 2 || !isSelector(params[0], "context", "Context") || len(results) != 2 || !isIdent(results[1], "error") {
+		return nil, fmt.Errorf("%v must have the signature (context.Context, *Input) (*pangea.PangeaResponse[Output], error)", name)
+	}
+	input, ok := pointerTo(params[1])
+	if !ok {
+		return nil, fmt.Errorf("%v must take a pointer to an input", name)
+	}
+	output, ok := pangeaResponseOf(results[0])
+	if !ok {
+		return nil, fmt.Errorf("%v must return a *pangea.PangeaResponse", name)
+	}
+	return &method{
+		Name:   name,
+		Input:  pkg + "." + input,
+		Output: pkg + "." + output,
+	}, nil
+}
+
+// flattenFields returns the type of every parameter, e.g. two for "a, b int".
+func flattenFields(fields *ast.FieldList) []ast.Expr {
+	var types []ast.Expr
+	if fields == nil {
+		return nil
+	}
+	for _, f := range fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, f.Type)
+		}
+	}
+	return types
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func isSelector(expr ast.Expr, pkg, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	return ok && isIdent(sel.X, pkg) && sel.Sel.Name == name
+}
+
+// pointerTo returns the name of T for *T, T being declared in the service package.
+func pointerTo(expr ast.Expr) (string, bool) {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// pangeaResponseOf returns the name of T for *pangea.PangeaResponse[T].
+func pangeaResponseOf(expr ast.Expr) (string, bool) {
+	star, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	index, ok := star.X.(*ast.IndexExpr)
+	if !ok || !isSelector(index.X, "pangea", "PangeaResponse") {
+		return "", false
+	}
+	ident, ok := index.Index.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// typeName returns the name of the fake of a service package, e.g. IpIntelClient for ip_intel.
+func typeName(pkg string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(pkg, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	b.WriteString("Client")
+	return b.String()
+}
+
+var fileTemplate = template.Must(template.New("mock").Parse(`// Code generated by dev/mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"{{.Module}}/service/{{.Package}}"
+)
+
+// {{.Type}} is a programmable fake of {{.Package}}.Client.
+type {{.Type}} struct {
+{{- range .Methods}}
+	{{.Name}}Method Method[{{.Input}}, {{.Output}}]
+{{- end}}
+}
+
+var _ {{.Package}}.Client = (*{{.Type}})(nil)
+
+// New{{.Type}} returns a fake of {{.Package}}.Client failing every call until its methods are programmed.
+func New{{.Type}}() *{{.Type}} {
+	c := &{{.Type}}{}
+{{- range .Methods}}
+	c.{{.Name}}Method.name = "{{$.Package}}.Client.{{.Name}}"
+{{- end}}
+	return c
+}
+{{range .Methods}}
+func (c *{{$.Type}}) {{.Name}}(ctx context.Context, input *{{.Input}}) (*pangea.PangeaResponse[{{.Output}}], error) {
+	return c.{{.Name}}Method.Call(ctx, input)
+}
+{{end}}
+// AssertExpectations fails the test if the expectations of the methods limited with Times
+// were not called as many times.
+func (c *{{.Type}}) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+	ok := true
+{{- range .Methods}}
+	ok = c.{{.Name}}Method.AssertExpectations(t) && ok
+{{- end}}
+	return ok
+}
+`))
+
+func writeService(dir string, svc *service) error {
+	var buf bytes.Buffer
+	err := fileTemplate.Execute(&buf, struct {
+		*service
+		Module string
+	}{svc, modulePath})
+	if err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("mockgen: invalid code generated for %v: %w", svc.Package, err)
+	}
+	return os.WriteFile(filepath.Join(dir, svc.Package+".go"), src, 0o644)
+}
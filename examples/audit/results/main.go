@@ -4,28 +4,18 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/pangeacyber/go-pangea/pangea"
 	"github.com/pangeacyber/go-pangea/service/audit"
 )
 
 func main() {
-	token := os.Getenv("AUDIT_AUTH_TOKEN")
-	if token == "" {
-		log.Fatal("Unauthorized: No token present")
-	}
-
-	configID := os.Getenv("AUDIT_CONFIG_ID")
-	if token == "" {
-		log.Fatal("Configuration: No config ID present")
+	cfg, err := pangea.ServiceConfigFromEnv("audit")
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	auditcli, err := audit.New(&pangea.Config{
-		Token:    token,
-		Domain:   os.Getenv("PANGEA_DOMAIN"),
-		CfgToken: configID,
-	})
+	auditcli, err := audit.New(cfg)
 	if err != nil {
 		log.Fatal("failed to create audit client")
 	}
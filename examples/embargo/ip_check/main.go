@@ -5,28 +5,18 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/pangeacyber/go-pangea/pangea"
 	"github.com/pangeacyber/go-pangea/service/embargo"
 )
 
 func main() {
-	token := os.Getenv("PANGEA_AUTH_TOKEN")
-	if token == "" {
-		log.Fatal("Unauthorized: No token present")
-	}
-
-	configID := os.Getenv("EMBARGO_CONFIG_ID")
-	if token == "" {
-		log.Fatal("Configuration: No config ID present")
+	cfg, err := pangea.ServiceConfigFromEnv("embargo")
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	embargocli, err := embargo.New(&pangea.Config{
-		Token:    token,
-		Domain:   os.Getenv("PANGEA_DOMAIN"),
-		CfgToken: configID,
-	})
+	embargocli, err := embargo.New(cfg)
 	if err != nil {
 		log.Fatal("failed to create embargo client")
 	}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/pangeacyber/go-pangea/pangea"
 	"github.com/pangeacyber/go-pangea/service/redact"
@@ -15,21 +14,12 @@ type yourCustomDataStruct struct {
 }
 
 func main() {
-	token := os.Getenv("PANGEA_AUTH_TOKEN")
-	if token == "" {
-		log.Fatal("Unauthorized: No token present")
-	}
-
-	configID := os.Getenv("REDACT_CONFIG_ID")
-	if token == "" {
-		log.Fatal("Configuration: No config ID present")
+	cfg, err := pangea.ServiceConfigFromEnv("redact")
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	redactcli, err := redact.New(&pangea.Config{
-		Token:    token,
-		Domain:   os.Getenv("PANGEA_DOMAIN"),
-		CfgToken: configID,
-	})
+	redactcli, err := redact.New(cfg)
 	if err != nil {
 		log.Fatal("failed to create redact client")
 	}
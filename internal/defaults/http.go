@@ -56,11 +56,10 @@ func HTTPPooledClient() *http.Client {
 	}
 }
 
-// HTTPClientWithRetries returns a new http.Client with similar default values to
-// http.Client, but with a non-shared Transport, idle connections disabled, and
-// keepalives disabled and retries.
-func HTTPClientWithRetries() *http.Client {
-	cli := &retryablehttp.Client{
+// RetryClient returns a new retryablehttp.Client with the default retry settings
+// and a non-shared pooled Transport.
+func RetryClient() *retryablehttp.Client {
+	return &retryablehttp.Client{
 		HTTPClient:   HTTPPooledClient(),
 		RetryWaitMin: 1 * time.Second,
 		RetryWaitMax: 30 * time.Second,
@@ -68,5 +67,11 @@ func HTTPClientWithRetries() *http.Client {
 		CheckRetry:   retryablehttp.DefaultRetryPolicy,
 		Backoff:      retryablehttp.DefaultBackoff,
 	}
-	return cli.StandardClient()
+}
+
+// HTTPClientWithRetries returns a new http.Client with similar default values to
+// http.Client, but with a non-shared Transport, idle connections disabled, and
+// keepalives disabled and retries.
+func HTTPClientWithRetries() *http.Client {
+	return RetryClient().StandardClient()
 }
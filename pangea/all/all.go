@@ -0,0 +1,217 @@
+// Package all provides a Client giving access to every Pangea service with a single config.
+//
+//	pangeacli := all.New(&pangea.Config{
+//		Token:  os.Getenv("PANGEA_TOKEN"),
+//		Domain: os.Getenv("PANGEA_DOMAIN"),
+//	}, all.ConfigIDs{
+//		Audit:  os.Getenv("AUDIT_CONFIG_ID"),
+//		Redact: os.Getenv("REDACT_CONFIG_ID"),
+//	})
+//
+//	auditcli, err := pangeacli.Audit()
+//
+// The service clients are built on first use and share the connections to Pangea.
+package all
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/pangeacyber/go-pangea/service/domain_intel"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+	"github.com/pangeacyber/go-pangea/service/file_intel"
+	"github.com/pangeacyber/go-pangea/service/ip_intel"
+	"github.com/pangeacyber/go-pangea/service/redact"
+	"github.com/pangeacyber/go-pangea/service/url_intel"
+)
+
+// ConfigIDs are the config IDs of the services, empty for the services without one.
+type ConfigIDs struct {
+	Audit       string
+	Redact      string
+	Embargo     string
+	IPIntel     string
+	URLIntel    string
+	DomainIntel string
+	FileIntel   string
+}
+
+// Client builds and holds the client of each service.
+// It is safe for concurrent use.
+type Client struct {
+	cfg *pangea.Config
+	ids ConfigIDs
+
+	auditOpts  []audit.Option
+	redactOpts []redact.Option
+
+	mu        sync.Mutex
+	transport http.RoundTripper
+
+	audit       lazy[*audit.Audit]
+	redact      lazy[*redact.Redact]
+	embargo     lazy[*embargo.Embargo]
+	ipIntel     lazy[*ip_intel.IpIntel]
+	urlIntel    lazy[*url_intel.UrlIntel]
+	domainIntel lazy[*domain_intel.DomainIntel]
+	fileIntel   lazy[*file_intel.FileIntel]
+}
+
+type Option func(*Client)
+
+// WithAuditOptions sets the options the audit client is built with.
+func WithAuditOptions(opts ...audit.Option) Option {
+	return func(c *Client) {
+		c.auditOpts = append(c.auditOpts, opts...)
+	}
+}
+
+// WithRedactOptions sets the options the redact client is built with.
+func WithRedactOptions(opts ...redact.Option) Option {
+	return func(c *Client) {
+		c.redactOpts = append(c.redactOpts, opts...)
+	}
+}
+
+// New returns a Client building the service clients with cfg and the config ID of
+// each service. Unless cfg has an HTTPClient or a Transport, the service clients share
+// a pooled transport keeping the connections to Pangea alive, built by cfg.NewTransport.
+func New(cfg *pangea.Config, ids ConfigIDs, opts ...Option) *Client {
+	c := &Client{
+		cfg: cfg.Copy(),
+		ids: ids,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Audit returns the client of the audit service.
+func (c *Client) Audit() (*audit.Audit, error) {
+	return c.audit.get(func() (*audit.Audit, error) {
+		cfg, err := c.serviceConfig(c.ids.Audit)
+		if err != nil {
+			return nil, err
+		}
+		return audit.New(cfg, c.auditOpts...)
+	})
+}
+
+// Redact returns the client of the redact service.
+func (c *Client) Redact() (*redact.Redact, error) {
+	return c.redact.get(func() (*redact.Redact, error) {
+		cfg, err := c.serviceConfig(c.ids.Redact)
+		if err != nil {
+			return nil, err
+		}
+		return redact.New(cfg, c.redactOpts...)
+	})
+}
+
+// Embargo returns the client of the embargo service.
+func (c *Client) Embargo() (*embargo.Embargo, error) {
+	return c.embargo.get(func() (*embargo.Embargo, error) {
+		cfg, err := c.serviceConfig(c.ids.Embargo)
+		if err != nil {
+			return nil, err
+		}
+		return embargo.New(cfg)
+	})
+}
+
+// IPIntel returns the client of the IP intel service.
+func (c *Client) IPIntel() (*ip_intel.IpIntel, error) {
+	return c.ipIntel.get(func() (*ip_intel.IpIntel, error) {
+		cfg, err := c.serviceConfig(c.ids.IPIntel)
+		if err != nil {
+			return nil, err
+		}
+		return ip_intel.New(cfg)
+	})
+}
+
+// URLIntel returns the client of the URL intel service.
+func (c *Client) URLIntel() (*url_intel.UrlIntel, error) {
+	return c.urlIntel.get(func() (*url_intel.UrlIntel, error) {
+		cfg, err := c.serviceConfig(c.ids.URLIntel)
+		if err != nil {
+			return nil, err
+		}
+		return url_intel.New(cfg)
+	})
+}
+
+// DomainIntel returns the client of the domain intel service.
+func (c *Client) DomainIntel() (*domain_intel.DomainIntel, error) {
+	return c.domainIntel.get(func() (*domain_intel.DomainIntel, error) {
+		cfg, err := c.serviceConfig(c.ids.DomainIntel)
+		if err != nil {
+			return nil, err
+		}
+		return domain_intel.New(cfg)
+	})
+}
+
+// FileIntel returns the client of the file intel service.
+func (c *Client) FileIntel() (*file_intel.FileIntel, error) {
+	return c.fileIntel.get(func() (*file_intel.FileIntel, error) {
+		cfg, err := c.serviceConfig(c.ids.FileIntel)
+		if err != nil {
+			return nil, err
+		}
+		return file_intel.New(cfg)
+	})
+}
+
+// CloseIdleConnections closes the idle connections of the shared transport.
+func (c *Client) CloseIdleConnections() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if transport, ok := c.transport.(interface{ CloseIdleConnections() }); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+// serviceConfig returns the config of a service client, with its config ID and the shared transport.
+func (c *Client) serviceConfig(cfgID string) (*pangea.Config, error) {
+	cfg := c.cfg.Copy()
+	cfg.CfgToken = cfgID
+	if cfg.HTTPClient == nil && cfg.Transport == nil {
+		transport, err := c.sharedTransport()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Transport = transport
+	}
+	return cfg, nil
+}
+
+func (c *Client) sharedTransport() (http.RoundTripper, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.transport == nil {
+		transport, err := c.cfg.NewTransport()
+		if err != nil {
+			return nil, err
+		}
+		c.transport = transport
+	}
+	return c.transport, nil
+}
+
+// lazy holds a value built on first use.
+type lazy[T any] struct {
+	once sync.Once
+	v    T
+	err  error
+}
+
+func (l *lazy[T]) get(build func() (T, error)) (T, error) {
+	l.once.Do(func() {
+		l.v, l.err = build()
+	})
+	return l.v, l.err
+}
@@ -0,0 +1,64 @@
+package all_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/all"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+	"github.com/pangeacyber/go-pangea/service/ip_intel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Shares_Transport_Between_Services(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	srv.SetVerdict("ip-intel", "93.231.182.110", pangeafake.Verdict{Verdict: "malicious", Score: 100})
+
+	pangeacli := all.New(srv.Config(), all.ConfigIDs{IPIntel: "pci_ipintel"})
+	defer pangeacli.CloseIdleConnections()
+
+	ipintel, err := pangeacli.IPIntel()
+	assert.NoError(t, err)
+	again, _ := pangeacli.IPIntel()
+	assert.Same(t, ipintel, again)
+	assert.Equal(t, "pci_ipintel", ipintel.Config.CfgToken)
+
+	resp, err := ipintel.Lookup(context.Background(), &ip_intel.IpLookupInput{Ip: "93.231.182.110"})
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", resp.Result.Data.Verdict)
+
+	embargocli, err := pangeacli.Embargo()
+	assert.NoError(t, err)
+	assert.Equal(t, "", embargocli.Config.CfgToken)
+	assert.NotNil(t, embargocli.Config.Transport)
+	assert.Same(t, ipintel.Config.Transport, embargocli.Config.Transport)
+
+	check, err := embargocli.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("CU")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pangea.IntValue(check.Result.Count))
+}
+
+func TestClient_Uses_HTTPClient_Of_Config(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	cfg := srv.Config()
+	cfg.HTTPClient = srv.Client()
+	pangeacli := all.New(cfg, all.ConfigIDs{})
+
+	auditcli, err := pangeacli.Audit()
+	assert.NoError(t, err)
+	assert.Same(t, cfg.HTTPClient, auditcli.Config.HTTPClient)
+	assert.Nil(t, auditcli.Config.Transport)
+}
+
+func TestClient_Returns_Error_Of_Invalid_Config(t *testing.T) {
+	cfg := &pangea.Config{Token: "TestToken", Domain: "pangea.test", CABundleFiles: []string{"missing.pem"}}
+	pangeacli := all.New(cfg, all.ConfigIDs{})
+
+	_, err := pangeacli.Redact()
+	assert.ErrorContains(t, err, "cannot read CA bundle")
+}
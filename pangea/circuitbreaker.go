@@ -0,0 +1,242 @@
+package pangea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request when the circuit breaker of the
+// service host is open.
+var ErrCircuitOpen = errors.New("pangea: circuit breaker is open")
+
+// CircuitState is the state of the circuit breaker of a service host.
+type CircuitState int
+
+const (
+	// Requests are sent, failures are counted.
+	CircuitClosed CircuitState = iota
+
+	// Requests fail with ErrCircuitOpen until OpenTimeout elapses.
+	CircuitOpen
+
+	// A limited number of probe requests are sent to find out if the host recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreakerConfig configures the circuit breaker of a Client.
+//
+// Failures are connection errors, timeouts and 5xx responses. Errors returned by Pangea
+// for an invalid request, such as validation errors, are not failures. Requests canceled by
+// the caller are neither failures nor successes.
+type CircuitBreakerConfig struct {
+	// Number of consecutive failures opening the circuit, defaults to 5
+	FailureThreshold int
+
+	// Time the circuit stays open before probing the host, defaults to 30s
+	OpenTimeout time.Duration
+
+	// Number of probe requests allowed while half-open, all of them must succeed to
+	// close the circuit again. Defaults to 1
+	HalfOpenMaxRequests int
+
+	// Called when the circuit of host changes state, e.g. to alert or switch to a fallback.
+	// It is called synchronously by the request changing the state, so it should not block.
+	OnStateChange func(host string, from, to CircuitState)
+}
+
+// circuitBreaker keeps the circuits of the hosts a Client sends requests to.
+type circuitBreaker struct {
+	cfg CircuitBreakerConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probes   int
+	passed   int
+
+	// generation changes with the state, so the results of requests sent in a previous
+	// state are ignored
+	generation int
+}
+
+// circuitOutcome is what the outcome of a request shows of the health of its host.
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+
+	// The request shows nothing, e.g. it was canceled by the caller. It is neither a success
+	// nor a failure, a probe only gives back its slot.
+	circuitNoOutcome
+)
+
+type stateChange struct {
+	host     string
+	from, to CircuitState
+}
+
+func newCircuitBreaker(cfg *CircuitBreakerConfig) *circuitBreaker {
+	if cfg == nil {
+		return nil
+	}
+	b := &circuitBreaker{
+		cfg:      *cfg,
+		now:      time.Now,
+		circuits: map[string]*circuit{},
+	}
+	if b.cfg.FailureThreshold < 1 {
+		b.cfg.FailureThreshold = 5
+	}
+	if b.cfg.OpenTimeout <= 0 {
+		b.cfg.OpenTimeout = 30 * time.Second
+	}
+	if b.cfg.HalfOpenMaxRequests < 1 {
+		b.cfg.HalfOpenMaxRequests = 1
+	}
+	return b
+}
+
+// allow returns ErrCircuitOpen if a request to host must not be sent. Otherwise the
+// returned func must be called with the outcome of the request once it is done.
+func (b *circuitBreaker) allow(host string) (done func(outcome circuitOutcome), err error) {
+	if b == nil {
+		return func(circuitOutcome) {}, nil
+	}
+
+	b.mu.Lock()
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{}
+		b.circuits[host] = c
+	}
+
+	var changes []stateChange
+	if c.state == CircuitOpen && b.now().Sub(c.openedAt) >= b.cfg.OpenTimeout {
+		changes = append(changes, b.transition(host, c, CircuitHalfOpen))
+	}
+	switch c.state {
+	case CircuitOpen:
+		err = fmt.Errorf("%w for %v", ErrCircuitOpen, host)
+	case CircuitHalfOpen:
+		if c.probes >= b.cfg.HalfOpenMaxRequests {
+			err = fmt.Errorf("%w for %v", ErrCircuitOpen, host)
+		} else {
+			c.probes++
+		}
+	}
+	generation := c.generation
+	b.mu.Unlock()
+
+	b.notify(changes)
+	if err != nil {
+		return nil, err
+	}
+	return func(outcome circuitOutcome) {
+		b.record(host, c, generation, outcome)
+	}, nil
+}
+
+func (b *circuitBreaker) record(host string, c *circuit, generation int, outcome circuitOutcome) {
+	b.mu.Lock()
+	var changes []stateChange
+	if c.generation == generation {
+		switch c.state {
+		case CircuitClosed:
+			switch outcome {
+			case circuitSuccess:
+				c.failures = 0
+			case circuitFailure:
+				if c.failures++; c.failures >= b.cfg.FailureThreshold {
+					changes = append(changes, b.transition(host, c, CircuitOpen))
+				}
+			}
+		case CircuitHalfOpen:
+			switch outcome {
+			case circuitSuccess:
+				if c.passed++; c.passed >= b.cfg.HalfOpenMaxRequests {
+					changes = append(changes, b.transition(host, c, CircuitClosed))
+				}
+			case circuitFailure:
+				changes = append(changes, b.transition(host, c, CircuitOpen))
+			case circuitNoOutcome:
+				// Another probe can be sent instead.
+				c.probes--
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(changes)
+}
+
+// transition moves c to state, b.mu must be held.
+func (b *circuitBreaker) transition(host string, c *circuit, state CircuitState) stateChange {
+	change := stateChange{host: host, from: c.state, to: state}
+	c.state = state
+	c.generation++
+	c.failures = 0
+	c.probes = 0
+	c.passed = 0
+	if state == CircuitOpen {
+		c.openedAt = b.now()
+	}
+	return change
+}
+
+func (b *circuitBreaker) notify(changes []stateChange) {
+	if b.cfg.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.cfg.OnStateChange(change.host, change.from, change.to)
+	}
+}
+
+// requestOutcome returns what the outcome of a request shows of the health of the host.
+// Requests canceled by the caller show nothing, they are neither successes nor failures.
+func requestOutcome(ctx context.Context, response *Response, err error) circuitOutcome {
+	if err == nil {
+		return circuitSuccess
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return circuitNoOutcome
+	}
+	var unmarshalErr *UnMarshalError
+	switch {
+	case response != nil && response.HTTPResponse != nil:
+		return statusOutcome(response.HTTPResponse.StatusCode)
+	case errors.As(err, &unmarshalErr) && unmarshalErr.HTTPResponse != nil:
+		return statusOutcome(unmarshalErr.HTTPResponse.StatusCode)
+	}
+	return circuitFailure
+}
+
+// statusOutcome returns the outcome of a request failing with the HTTP status code.
+func statusOutcome(statusCode int) circuitOutcome {
+	if statusCode >= 500 {
+		return circuitFailure
+	}
+	return circuitSuccess
+}
@@ -0,0 +1,207 @@
+package pangea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+type stateChanges struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (s *stateChanges) record(host string, from, to pangea.CircuitState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.changes = append(s.changes, fmt.Sprintf("%v->%v", from, to))
+}
+
+func (s *stateChanges) get() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.changes...)
+}
+
+// flakyHandler fails with 503 while failing is true.
+type flakyHandler struct {
+	mu      sync.Mutex
+	failing bool
+	calls   int
+}
+
+func (h *flakyHandler) setFailing(failing bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failing = failing
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.calls++
+	if h.failing {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 503, "status": "ServiceNotAvailable", "result": null}`)
+		return
+	}
+	okHandler(w, r)
+}
+
+func doTestRequest(client *pangea.Client) error {
+	req, err := client.NewRequest("POST", "test", nil)
+	if err != nil {
+		return err
+	}
+	_, err = client.Do(context.Background(), req, nil)
+	return err
+}
+
+func TestDo_With_CircuitBreaker_Opens_After_Consecutive_Failures(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	handler := &flakyHandler{failing: true}
+	mux.Handle("/test", handler)
+
+	changes := &stateChanges{}
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CircuitBreaker = &pangea.CircuitBreakerConfig{
+		FailureThreshold: 3,
+		OpenTimeout:      time.Hour,
+		OnStateChange:    changes.record,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	for i := 0; i < 3; i++ {
+		err := doTestRequest(client)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, pangea.ErrCircuitOpen)
+	}
+
+	err := doTestRequest(client)
+	assert.ErrorIs(t, err, pangea.ErrCircuitOpen)
+	assert.Equal(t, 3, handler.calls)
+	assert.Equal(t, []string{"closed->open"}, changes.get())
+}
+
+func TestDo_With_CircuitBreaker_Closes_After_Successful_Probe(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	handler := &flakyHandler{failing: true}
+	mux.Handle("/test", handler)
+
+	changes := &stateChanges{}
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CircuitBreaker = &pangea.CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange:    changes.record,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	assert.Error(t, doTestRequest(client))
+	assert.ErrorIs(t, doTestRequest(client), pangea.ErrCircuitOpen)
+
+	handler.setFailing(false)
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, doTestRequest(client))
+	assert.NoError(t, doTestRequest(client))
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes.get())
+}
+
+func TestDo_With_CircuitBreaker_Reopens_After_Failed_Probe(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	handler := &flakyHandler{failing: true}
+	mux.Handle("/test", handler)
+
+	changes := &stateChanges{}
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CircuitBreaker = &pangea.CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange:    changes.record,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	assert.Error(t, doTestRequest(client))
+	time.Sleep(30 * time.Millisecond)
+	err := doTestRequest(client)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, pangea.ErrCircuitOpen)
+	assert.ErrorIs(t, doTestRequest(client), pangea.ErrCircuitOpen)
+	assert.Equal(t, 2, handler.calls)
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->open"}, changes.get())
+}
+
+func TestDo_With_CircuitBreaker_Ignores_Client_Errors(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 400, "status": "ValidationError", "result": null}`)
+	})
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CircuitBreaker = &pangea.CircuitBreakerConfig{FailureThreshold: 1}
+	client := pangea.NewClient("service", cfg)
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, doTestRequest(client), pangea.ErrValidation)
+	}
+}
+
+func TestCircuitState_String(t *testing.T) {
+	assert.Equal(t, "closed", pangea.CircuitClosed.String())
+	assert.Equal(t, "open", pangea.CircuitOpen.String())
+	assert.Equal(t, "half-open", pangea.CircuitHalfOpen.String())
+}
+
+func TestDo_With_CircuitBreaker_Ignores_Canceled_Probe(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	handler := &flakyHandler{failing: true}
+	var hanging atomic.Bool
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		if hanging.Load() {
+			// The host hangs until the request is canceled.
+			<-r.Context().Done()
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	changes := &stateChanges{}
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CircuitBreaker = &pangea.CircuitBreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange:    changes.record,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	assert.Error(t, doTestRequest(client))
+	time.Sleep(30 * time.Millisecond)
+
+	hanging.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(ctx, req, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The canceled probe neither closes the circuit nor holds the probe slot.
+	assert.Equal(t, []string{"closed->open", "open->half-open"}, changes.get())
+	hanging.Store(false)
+	handler.setFailing(false)
+	assert.NoError(t, doTestRequest(client))
+	assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes.get())
+}
@@ -0,0 +1,298 @@
+package pangea
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables read by ConfigFromEnv and ServiceConfigFromEnv.
+const (
+	EnvToken        = "PANGEA_TOKEN"
+	EnvDomain       = "PANGEA_DOMAIN"
+	EnvInsecure     = "PANGEA_INSECURE"
+	EnvEnvironment  = "PANGEA_ENVIRONMENT"
+	EnvRetry        = "PANGEA_RETRY"
+	EnvRetryMax     = "PANGEA_RETRY_MAX"
+	EnvRetryWaitMin = "PANGEA_RETRY_WAIT_MIN"
+	EnvRetryWaitMax = "PANGEA_RETRY_WAIT_MAX"
+)
+
+// ConfigFromEnv creates a Config from the PANGEA_* environment variables.
+//
+//	PANGEA_TOKEN and PANGEA_DOMAIN are required, a ConfigError is returned if they are missing.
+//	PANGEA_INSECURE and PANGEA_RETRY are booleans, PANGEA_RETRY_MAX is an integer and
+//	PANGEA_RETRY_WAIT_MIN and PANGEA_RETRY_WAIT_MAX are durations such as "500ms".
+func ConfigFromEnv() (*Config, error) {
+	cfg, err := configFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig("environment", cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ServiceConfigFromEnv creates a Config for service from the environment.
+//
+//	On top of the variables read by ConfigFromEnv, the config ID of the service is read from
+//	<SERVICE>_CONFIG_ID and <SERVICE>_AUTH_TOKEN overrides PANGEA_TOKEN if set, where <SERVICE>
+//	is the upper cased service name with dashes replaced by underscores, e.g. IP_INTEL_CONFIG_ID.
+func ServiceConfigFromEnv(service string) (*Config, error) {
+	cfg, err := configFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	prefix := envServicePrefix(service)
+	if token := os.Getenv(prefix + "_AUTH_TOKEN"); token != "" {
+		cfg.Token = token
+	}
+	cfg.CfgToken = os.Getenv(prefix + "_CONFIG_ID")
+	if err := validateConfig("environment", cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func envServicePrefix(service string) string {
+	return strings.ToUpper(strings.ReplaceAll(service, "-", "_"))
+}
+
+func configFromEnv() (*Config, error) {
+	cfg := &Config{
+		Token:      os.Getenv(EnvToken),
+		Domain:     os.Getenv(EnvDomain),
+		Enviroment: os.Getenv(EnvEnvironment),
+	}
+
+	var err error
+	if cfg.Insecure, err = envBool(EnvInsecure); err != nil {
+		return nil, err
+	}
+	if cfg.Retry, err = envBool(EnvRetry); err != nil {
+		return nil, err
+	}
+
+	retryCfg := RetryConfig{}
+	if retryCfg.RetryMax, err = envInt(EnvRetryMax); err != nil {
+		return nil, err
+	}
+	if retryCfg.RetryWaitMin, err = envDuration(EnvRetryWaitMin); err != nil {
+		return nil, err
+	}
+	if retryCfg.RetryWaitMax, err = envDuration(EnvRetryWaitMax); err != nil {
+		return nil, err
+	}
+	if retryCfg != (RetryConfig{}) {
+		cfg.RetryConfig = &retryCfg
+	}
+	return cfg, nil
+}
+
+func envBool(name string) (bool, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("pangea: invalid %v: %w", name, err)
+	}
+	return b, nil
+}
+
+func envInt(name string) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("pangea: invalid %v: %w", name, err)
+	}
+	return i, nil
+}
+
+func envDuration(name string) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("pangea: invalid %v: %w", name, err)
+	}
+	return d, nil
+}
+
+// ConfigFile holds named profiles loaded from a YAML or JSON file.
+//
+// Example:
+//
+//	default_profile: production
+//	profiles:
+//	  production:
+//	    token: pts_...
+//	    domain: aws.us.pangea.cloud
+//	    retry: true
+//	    retry_config:
+//	      retry_max: 4
+//	      retry_wait_min: 1s
+//	      retry_wait_max: 30s
+//	    config_ids:
+//	      audit: pci_...
+//	      ip-intel: pci_...
+type ConfigFile struct {
+	// The profile used when no profile name is given
+	DefaultProfile string `json:"default_profile" yaml:"default_profile"`
+
+	// The profiles by name
+	Profiles map[string]*Profile `json:"profiles" yaml:"profiles"`
+
+	// the file the profiles were loaded from, used in errors
+	name string
+}
+
+type Profile struct {
+	// The Bearer token used to authenticate requests.
+	Token string `json:"token" yaml:"token"`
+
+	// Base domain for API requests.
+	Domain string `json:"domain" yaml:"domain"`
+
+	// Set to true to use plain http
+	Insecure bool `json:"insecure" yaml:"insecure"`
+
+	// Set to "local" for testing locally
+	Environment string `json:"environment" yaml:"environment"`
+
+	// Additional headers to be sent with the requests.
+	AdditionalHeaders map[string]string `json:"additional_headers" yaml:"additional_headers"`
+
+	// if it should retry requests
+	Retry bool `json:"retry" yaml:"retry"`
+
+	// Retry config defaults to a base retry option
+	RetryConfig *ProfileRetryConfig `json:"retry_config" yaml:"retry_config"`
+
+	// The config IDs by service name, e.g. "audit" or "ip-intel"
+	ConfigIDs map[string]string `json:"config_ids" yaml:"config_ids"`
+}
+
+type ProfileRetryConfig struct {
+	RetryWaitMin string `json:"retry_wait_min" yaml:"retry_wait_min"` // Minimum time to wait, e.g. "1s"
+	RetryWaitMax string `json:"retry_wait_max" yaml:"retry_wait_max"` // Maximum time to wait, e.g. "30s"
+	RetryMax     int    `json:"retry_max" yaml:"retry_max"`           // Maximum number of retries
+}
+
+// LoadConfigFile reads the profiles from the named file.
+// Files with a .json extension are decoded as JSON, any other file as YAML.
+func LoadConfigFile(name string) (*ConfigFile, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("pangea: cannot read config file: %w", err)
+	}
+
+	f := &ConfigFile{name: name}
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = json.Unmarshal(b, f)
+	} else {
+		err = yaml.Unmarshal(b, f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pangea: cannot decode config file %v: %w", name, err)
+	}
+	return f, nil
+}
+
+// ConfigFromFile loads the named file and returns the config of service in profile.
+// See ConfigFile.Config.
+func ConfigFromFile(name, profile, service string) (*Config, error) {
+	f, err := LoadConfigFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.Config(profile, service)
+}
+
+// Config returns the config of service in the named profile, or in the default
+// profile if profile is empty. The service may be empty for a config without a config ID.
+// A ConfigError is returned if the token or the domain are missing.
+func (f *ConfigFile) Config(profile, service string) (*Config, error) {
+	if profile == "" {
+		profile = f.DefaultProfile
+	}
+	p, ok := f.Profiles[profile]
+	if !ok || p == nil {
+		return nil, fmt.Errorf("pangea: profile %q not found in config file %v", profile, f.name)
+	}
+
+	base, err := p.config()
+	if err != nil {
+		return nil, fmt.Errorf("pangea: invalid profile %q in config file %v: %w", profile, f.name, err)
+	}
+
+	// Insecure is always merged in, so the service config goes first.
+	cfg := &Config{CfgToken: p.ConfigIDs[service]}
+	cfg = cfg.Copy(base)
+	if err := validateConfig(fmt.Sprintf("profile %q in config file %v", profile, f.name), cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (p *Profile) config() (*Config, error) {
+	cfg := &Config{
+		Token:             p.Token,
+		Domain:            p.Domain,
+		Insecure:          p.Insecure,
+		Enviroment:        p.Environment,
+		AdditionalHeaders: p.AdditionalHeaders,
+		Retry:             p.Retry,
+	}
+	if p.RetryConfig != nil {
+		cfg.RetryConfig = &RetryConfig{
+			RetryMax: p.RetryConfig.RetryMax,
+		}
+		var err error
+		if cfg.RetryConfig.RetryWaitMin, err = parseDuration("retry_wait_min", p.RetryConfig.RetryWaitMin); err != nil {
+			return nil, err
+		}
+		if cfg.RetryConfig.RetryWaitMax, err = parseDuration("retry_wait_max", p.RetryConfig.RetryWaitMax); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func parseDuration(field, v string) (time.Duration, error) {
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %w", field, err)
+	}
+	return d, nil
+}
+
+func validateConfig(source string, cfg *Config) error {
+	var missing []string
+	if cfg.Token == "" {
+		missing = append(missing, "token")
+	}
+	if cfg.Domain == "" {
+		missing = append(missing, "domain")
+	}
+	if len(missing) > 0 {
+		return &ConfigError{Source: source, Missing: missing}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package pangea_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("PANGEA_TOKEN", "token")
+	t.Setenv("PANGEA_DOMAIN", "aws.us.pangea.cloud")
+	t.Setenv("PANGEA_RETRY", "true")
+	t.Setenv("PANGEA_RETRY_MAX", "3")
+	t.Setenv("PANGEA_RETRY_WAIT_MIN", "100ms")
+
+	cfg, err := pangea.ConfigFromEnv()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token", cfg.Token)
+	assert.Equal(t, "aws.us.pangea.cloud", cfg.Domain)
+	assert.True(t, cfg.Retry)
+	assert.Equal(t, &pangea.RetryConfig{RetryMax: 3, RetryWaitMin: 100 * time.Millisecond}, cfg.RetryConfig)
+}
+
+func TestServiceConfigFromEnv(t *testing.T) {
+	t.Setenv("PANGEA_TOKEN", "token")
+	t.Setenv("PANGEA_DOMAIN", "aws.us.pangea.cloud")
+	t.Setenv("IP_INTEL_CONFIG_ID", "ip-intel-config-id")
+	t.Setenv("IP_INTEL_AUTH_TOKEN", "ip-intel-token")
+
+	cfg, err := pangea.ServiceConfigFromEnv("ip-intel")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ip-intel-token", cfg.Token)
+	assert.Equal(t, "ip-intel-config-id", cfg.CfgToken)
+	assert.Nil(t, cfg.RetryConfig)
+}
+
+func TestConfigFromEnv_When_Fields_Are_Missing_It_Returns_ConfigError(t *testing.T) {
+	t.Setenv("PANGEA_TOKEN", "")
+	t.Setenv("PANGEA_DOMAIN", "")
+
+	_, err := pangea.ConfigFromEnv()
+
+	var cfgErr *pangea.ConfigError
+	if assert.True(t, errors.As(err, &cfgErr)) {
+		assert.Equal(t, []string{"token", "domain"}, cfgErr.Missing)
+	}
+}
+
+func TestConfigFromEnv_When_Value_Is_Invalid_It_Returns_Error(t *testing.T) {
+	t.Setenv("PANGEA_TOKEN", "token")
+	t.Setenv("PANGEA_DOMAIN", "aws.us.pangea.cloud")
+	t.Setenv("PANGEA_RETRY_MAX", "many")
+
+	_, err := pangea.ConfigFromEnv()
+
+	assert.ErrorContains(t, err, "PANGEA_RETRY_MAX")
+}
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestConfigFromFile_YAML(t *testing.T) {
+	path := writeConfigFile(t, "pangea.yaml", `
+default_profile: production
+profiles:
+  production:
+    token: prod-token
+    domain: aws.us.pangea.cloud
+    insecure: true
+    retry: true
+    retry_config:
+      retry_max: 4
+      retry_wait_min: 1s
+      retry_wait_max: 30s
+    config_ids:
+      audit: audit-config-id
+  staging:
+    token: staging-token
+`)
+
+	cfg, err := pangea.ConfigFromFile(path, "", "audit")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "prod-token", cfg.Token)
+	assert.Equal(t, "aws.us.pangea.cloud", cfg.Domain)
+	assert.Equal(t, "audit-config-id", cfg.CfgToken)
+	assert.True(t, cfg.Insecure)
+	assert.True(t, cfg.Retry)
+	assert.Equal(t, &pangea.RetryConfig{RetryMax: 4, RetryWaitMin: time.Second, RetryWaitMax: 30 * time.Second}, cfg.RetryConfig)
+
+	_, err = pangea.ConfigFromFile(path, "staging", "audit")
+	var cfgErr *pangea.ConfigError
+	if assert.True(t, errors.As(err, &cfgErr)) {
+		assert.Equal(t, []string{"domain"}, cfgErr.Missing)
+	}
+
+	_, err = pangea.ConfigFromFile(path, "unknown", "audit")
+	assert.ErrorContains(t, err, `profile "unknown" not found`)
+}
+
+func TestConfigFromFile_JSON(t *testing.T) {
+	path := writeConfigFile(t, "pangea.json", `{
+		"profiles": {
+			"default": {
+				"token": "token",
+				"domain": "aws.us.pangea.cloud",
+				"config_ids": {"ip-intel": "ip-intel-config-id"},
+				"retry_config": {"retry_wait_min": "soon"}
+			}
+		}
+	}`)
+
+	f, err := pangea.LoadConfigFile(path)
+	assert.NoError(t, err)
+
+	_, err = f.Config("default", "ip-intel")
+	assert.ErrorContains(t, err, "invalid retry_wait_min")
+}
+
+func TestConfigCopy_Keeps_HTTPClient(t *testing.T) {
+	httpClient := &http.Client{}
+	cfg := &pangea.Config{Token: "token", Domain: "domain.test", HTTPClient: httpClient}
+
+	assert.Same(t, httpClient, cfg.Copy().HTTPClient)
+	assert.Same(t, httpClient, pangea.NewClient("service", cfg).Config.HTTPClient)
+
+	other := &http.Client{}
+	merged := cfg.Copy()
+	merged.MergeIn(&pangea.Config{HTTPClient: other})
+	assert.Same(t, other, merged.HTTPClient)
+}
@@ -0,0 +1,86 @@
+package pangea
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// EndpointResolver resolves the base URL of a service, e.g. "https://audit.aws.us.pangea.cloud".
+// The paths of the endpoints, e.g. "v1/log", are appended to it.
+type EndpointResolver interface {
+	ResolveEndpoint(service string) (*url.URL, error)
+}
+
+// EndpointResolverFunc is a function implementing EndpointResolver.
+type EndpointResolverFunc func(service string) (*url.URL, error)
+
+func (f EndpointResolverFunc) ResolveEndpoint(service string) (*url.URL, error) {
+	return f(service)
+}
+
+// ServiceNamePlaceholder is replaced by the name of the service in Config.URLTemplate.
+const ServiceNamePlaceholder = "{service}"
+
+// resolveEndpoint returns the base URL of service from the config, checking it is valid.
+// The EndpointResolver takes precedence over ServiceURLs, then URLTemplate and finally Domain.
+func resolveEndpoint(cfg *Config, service string) (*url.URL, error) {
+	var u *url.URL
+	var err error
+	switch {
+	case cfg.EndpointResolver != nil:
+		u, err = cfg.EndpointResolver.ResolveEndpoint(service)
+	case cfg.ServiceURLs[service] != "":
+		u, err = url.Parse(cfg.ServiceURLs[service])
+	case cfg.URLTemplate != "":
+		u, err = url.Parse(strings.ReplaceAll(cfg.URLTemplate, ServiceNamePlaceholder, service))
+	default:
+		u, err = domainEndpoint(cfg, service)
+	}
+	if err == nil {
+		err = validateEndpoint(u)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pangea: invalid endpoint of %v: %w", service, err)
+	}
+	return u, nil
+}
+
+// domainEndpoint returns the URL of service on the domain, e.g. "https://audit.aws.us.pangea.cloud",
+// or the domain itself in the local environment.
+func domainEndpoint(cfg *Config, service string) (*url.URL, error) {
+	domain := strings.TrimSuffix(cfg.Domain, "/")
+	if domain == "" {
+		return nil, errors.New("missing domain")
+	}
+	if strings.Contains(domain, "://") || strings.Contains(domain, " ") {
+		return nil, fmt.Errorf("invalid domain %q, it must be a host name without scheme", cfg.Domain)
+	}
+
+	scheme := "https"
+	if cfg.Insecure {
+		scheme = "http"
+	}
+	if cfg.Enviroment == "local" {
+		// If we are testing locally do not use service
+		return url.Parse(fmt.Sprintf("%s://%s", scheme, domain))
+	}
+	return url.Parse(fmt.Sprintf("%s://%s.%s", scheme, service, domain))
+}
+
+func validateEndpoint(u *url.URL) error {
+	if u == nil {
+		return errors.New("no URL resolved")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme in %q", u)
+	}
+	if u.Hostname() == "" || strings.HasSuffix(u.Host, ":") {
+		return fmt.Errorf("invalid host in %q", u)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("query or fragment in %q", u)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package pangea_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequest_Resolves_Endpoint(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *pangea.Config
+		want string
+	}{
+		{
+			name: "domain",
+			cfg:  &pangea.Config{Domain: "aws.eu.pangea.cloud/"},
+			want: "https://audit.aws.eu.pangea.cloud/v1/log",
+		},
+		{
+			name: "local environment",
+			cfg:  &pangea.Config{Domain: "localhost:8000", Insecure: true, Enviroment: "local"},
+			want: "http://localhost:8000/v1/log",
+		},
+		{
+			name: "service URL",
+			cfg: &pangea.Config{
+				Domain:      "aws.us.pangea.cloud",
+				ServiceURLs: map[string]string{"audit": "https://gateway.internal/pangea/audit/"},
+			},
+			want: "https://gateway.internal/pangea/audit/v1/log",
+		},
+		{
+			name: "service URL of another service",
+			cfg: &pangea.Config{
+				Domain:      "aws.us.pangea.cloud",
+				ServiceURLs: map[string]string{"redact": "https://gateway.internal/pangea/redact"},
+			},
+			want: "https://audit.aws.us.pangea.cloud/v1/log",
+		},
+		{
+			name: "URL template",
+			cfg:  &pangea.Config{URLTemplate: "https://gateway.internal/{service}/api"},
+			want: "https://gateway.internal/audit/api/v1/log",
+		},
+		{
+			name: "resolver",
+			cfg: &pangea.Config{
+				URLTemplate: "https://gateway.internal/{service}",
+				EndpointResolver: pangea.EndpointResolverFunc(func(service string) (*url.URL, error) {
+					return url.Parse("http://127.0.0.1:8080/" + service)
+				}),
+			},
+			want: "http://127.0.0.1:8080/audit/v1/log",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := pangea.NewClient("audit", tt.cfg)
+			assert.NoError(t, client.Err())
+			req, err := client.NewRequest("POST", "v1/log", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, req.URL.String())
+		})
+	}
+}
+
+func TestNew_With_Invalid_Endpoint_Returns_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *pangea.Config
+		want string
+	}{
+		{"missing domain", &pangea.Config{}, "missing domain"},
+		{"invalid domain", &pangea.Config{Domain: "htt://   "}, "invalid endpoint of audit"},
+		{"relative service URL", &pangea.Config{ServiceURLs: map[string]string{"audit": "/pangea/audit"}}, "unsupported scheme"},
+		{"URL template with query", &pangea.Config{URLTemplate: "https://{service}.internal?x=1"}, "query or fragment"},
+		{"resolver error", &pangea.Config{
+			EndpointResolver: pangea.EndpointResolverFunc(func(service string) (*url.URL, error) {
+				return nil, errors.New("unknown service")
+			}),
+		}, "unknown service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := audit.New(tt.cfg)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
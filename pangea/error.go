@@ -3,8 +3,30 @@ package pangea
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sentinel errors matching the category of an APIError with errors.Is.
+//
+// Example:
+//
+//	_, err := auditcli.Log(ctx, input)
+//	if errors.Is(err, pangea.ErrRateLimited) {
+//		// back off
+//	}
+var (
+	ErrValidation        = errors.New("pangea: validation error")
+	ErrUnauthorized      = errors.New("pangea: unauthorized")
+	ErrForbidden         = errors.New("pangea: forbidden")
+	ErrNotFound          = errors.New("pangea: not found")
+	ErrRateLimited       = errors.New("pangea: rate limited")
+	ErrServiceNotEnabled = errors.New("pangea: service not enabled")
+	ErrProviderError     = errors.New("pangea: provider error")
 )
 
 type APIError struct {
@@ -52,6 +74,141 @@ func (e *APIError) Error() string {
 	return b.String()
 }
 
+// Unwrap returns the underlying error, if any.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns whether target is the sentinel error of the category of this error.
+func (e *APIError) Is(target error) bool {
+	category := e.Category()
+	return category != nil && category == target
+}
+
+// Category returns the sentinel error matching the Pangea status of the response,
+// or its HTTP status code if the status is not known. It returns nil if there is no match.
+func (e *APIError) Category() error {
+	if e.ResponseHeader != nil {
+		switch status := StringValue(e.ResponseHeader.Status); {
+		case status == "ValidationError":
+			return ErrValidation
+		case status == "Unauthorized":
+			return ErrUnauthorized
+		case status == "ServiceNotEnabled":
+			return ErrServiceNotEnabled
+		case status == "ProviderError":
+			return ErrProviderError
+		case status == "TooManyRequests" || status == "NoCredit":
+			return ErrRateLimited
+		case strings.HasPrefix(status, "Forbidden"):
+			return ErrForbidden
+		case strings.HasSuffix(status, "NotFound"):
+			return ErrNotFound
+		}
+	}
+
+	code := 0
+	if e.HTTPResponse != nil {
+		code = e.HTTPResponse.StatusCode
+	} else if e.ResponseHeader != nil {
+		code = IntValue(e.ResponseHeader.StatusCode)
+	}
+	switch code {
+	case http.StatusBadRequest:
+		return ErrValidation
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// FieldError describes why a field of a request failed validation.
+type FieldError struct {
+	// The error code, e.g. "MissingRequiredProperty"
+	Code *string `json:"code"`
+
+	// A description of the error
+	Detail *string `json:"detail"`
+
+	// The JSON pointer to the invalid field, e.g. "/event/message"
+	Source *string `json:"source"`
+
+	// The path of the invalid field
+	Path *string `json:"path"`
+}
+
+// ValidationError is returned when Pangea rejects a request with the ValidationError status.
+type ValidationError struct {
+	APIError
+
+	// The errors of the invalid fields, if Pangea reported them
+	Errors []*FieldError
+}
+
+func newValidationError(apiErr APIError) *ValidationError {
+	var result struct {
+		Errors []*FieldError `json:"errors"`
+	}
+	// The details are best effort, the error is returned even if they cannot be decoded.
+	_ = json.Unmarshal(apiErr.Result, &result)
+	return &ValidationError{
+		APIError: apiErr,
+		Errors:   result.Errors,
+	}
+}
+
+// As allows errors.As to find the APIError of a ValidationError.
+func (e *ValidationError) As(target any) bool {
+	if t, ok := target.(**APIError); ok {
+		*t = &e.APIError
+		return true
+	}
+	return false
+}
+
+// RateLimitedError is returned when Pangea throttles a request or the quota is exhausted.
+type RateLimitedError struct {
+	APIError
+
+	// The time to wait before retrying as requested by the Retry-After header, 0 if absent
+	RetryAfter time.Duration
+}
+
+// As allows errors.As to find the APIError of a RateLimitedError.
+func (e *RateLimitedError) As(target any) bool {
+	if t, ok := target.(**APIError); ok {
+		*t = &e.APIError
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, in seconds or as an HTTP date.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 type UnMarshalError struct {
 	APIError
 	Bytes []byte
@@ -112,3 +269,16 @@ func (e *AcceptedError) Is(target error) bool {
 func (e *AcceptedError) ReqID() string {
 	return StringValue(e.RequestID)
 }
+
+// ConfigError is returned when a loaded configuration misses required fields.
+type ConfigError struct {
+	// Where the configuration was loaded from
+	Source string
+
+	// The missing fields
+	Missing []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("pangea: invalid config from %v: missing %v", e.Source, strings.Join(e.Missing, ", "))
+}
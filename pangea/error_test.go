@@ -0,0 +1,117 @@
+package pangea_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func doWithResponse(t *testing.T, code int, headers map[string]string, body string) error {
+	t.Helper()
+	mux, url, teardown := pangeatesting.SetupServer()
+	t.Cleanup(teardown)
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(code)
+		fmt.Fprint(w, body)
+	})
+
+	client := testClient(t, url)
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	return err
+}
+
+func TestDo_When_Server_Returns_ValidationError_It_Returns_Field_Errors(t *testing.T) {
+	err := doWithResponse(t, http.StatusBadRequest, nil, `{
+		"request_id": "some-id",
+		"status_code": 400,
+		"status": "ValidationError",
+		"result": {
+			"errors": [
+				{
+					"code": "MissingRequiredProperty",
+					"detail": "'message' is a required property",
+					"source": "/event/message"
+				}
+			]
+		},
+		"summary": "There was 1 error(s) in the given payload"
+	}`)
+
+	assert.ErrorIs(t, err, pangea.ErrValidation)
+	assert.NotErrorIs(t, err, pangea.ErrUnauthorized)
+
+	var validationErr *pangea.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) && assert.Len(t, validationErr.Errors, 1) {
+		assert.Equal(t, "MissingRequiredProperty", pangea.StringValue(validationErr.Errors[0].Code))
+		assert.Equal(t, "/event/message", pangea.StringValue(validationErr.Errors[0].Source))
+	}
+
+	var apiErr *pangea.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "some-id", pangea.StringValue(apiErr.ResponseHeader.RequestID))
+	}
+}
+
+func TestDo_When_Server_Returns_429_It_Returns_RateLimitedError(t *testing.T) {
+	err := doWithResponse(t, http.StatusTooManyRequests, map[string]string{"Retry-After": "7"}, `{
+		"request_id": "some-id",
+		"status_code": 429,
+		"status": "TooManyRequests",
+		"result": null
+	}`)
+
+	assert.ErrorIs(t, err, pangea.ErrRateLimited)
+
+	var rateLimitedErr *pangea.RateLimitedError
+	if assert.ErrorAs(t, err, &rateLimitedErr) {
+		assert.Equal(t, 7*time.Second, rateLimitedErr.RetryAfter)
+	}
+}
+
+func TestAPIError_Category(t *testing.T) {
+	tests := []struct {
+		status string
+		code   int
+		want   error
+	}{
+		{"ValidationError", http.StatusBadRequest, pangea.ErrValidation},
+		{"Unauthorized", http.StatusUnauthorized, pangea.ErrUnauthorized},
+		{"ServiceNotEnabled", http.StatusForbidden, pangea.ErrServiceNotEnabled},
+		{"ForbiddenVaultOperation", http.StatusBadRequest, pangea.ErrForbidden},
+		{"TreeNotFound", http.StatusBadRequest, pangea.ErrNotFound},
+		{"ProviderError", http.StatusBadRequest, pangea.ErrProviderError},
+		{"NoCredit", http.StatusBadRequest, pangea.ErrRateLimited},
+		{"error", http.StatusForbidden, pangea.ErrForbidden},
+		{"error", http.StatusNotFound, pangea.ErrNotFound},
+		{"error", http.StatusInternalServerError, nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v_%v", tt.status, tt.code), func(t *testing.T) {
+			err := &pangea.APIError{
+				HTTPResponse:   &http.Response{StatusCode: tt.code},
+				ResponseHeader: &pangea.ResponseHeader{Status: pangea.String(tt.status)},
+			}
+			assert.Equal(t, tt.want, err.Category())
+			if tt.want != nil {
+				assert.True(t, errors.Is(err, tt.want))
+			}
+		})
+	}
+}
+
+func TestAPIError_Unwraps_Underlying_Error(t *testing.T) {
+	err := pangea.NewAPIError(context.DeadlineExceeded, nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,111 @@
+package pangea
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ErrIdempotencyKeyReused is returned by Do when the idempotency key of the context was already
+// sent with a different request.
+var ErrIdempotencyKeyReused = errors.New("pangea: idempotency key reused by a different request")
+
+type idempotencyKeyKey struct{}
+
+// contextKey is the idempotency key of a context, bound to the first request sent with it.
+type contextKey struct {
+	key string
+
+	mu      sync.Mutex
+	request string // fingerprint of the request the key is bound to, empty until sent
+}
+
+// bind binds the key to the request with fingerprint, it fails if it is bound to another one.
+func (k *contextKey) bind(fingerprint string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.request == "" {
+		k.request = fingerprint
+		return nil
+	}
+	if k.request != fingerprint {
+		return fmt.Errorf("%w: %q", ErrIdempotencyKeyReused, k.key)
+	}
+	return nil
+}
+
+// WithIdempotencyKey returns a context sending a write with the idempotency key, so Pangea
+// processes it at most once, e.g. across restarts of the caller. The key is only sent with the
+// requests to the NonIdempotentPaths of the client, e.g. "v1/log", the other requests made with
+// the context are sent without it. The key identifies a single write: Do sends it with the first
+// write made with the context, and the same write can be sent again with it, but Do fails with
+// ErrIdempotencyKeyReused for any other write, as Pangea would process it as a duplicate of the
+// first one and drop it.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, &contextKey{key: key})
+}
+
+// IdempotencyKeyFromContext returns the idempotency key set with WithIdempotencyKey, if any.
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	if k, ok := ctx.Value(idempotencyKeyKey{}).(*contextKey); ok && k.key != "" {
+		return k.key, true
+	}
+	return "", false
+}
+
+// idempotencyKey returns the idempotency key of req, a request to a non-idempotent endpoint:
+// the key of the context, if it is not bound to another request, or a new key. It is empty for
+// the other requests, which are not bound to the key of the context.
+func (c *Client) idempotencyKey(ctx context.Context, req *http.Request) (string, error) {
+	if c.isIdempotent(requestPath(req)) {
+		return "", nil
+	}
+	if k, ok := ctx.Value(idempotencyKeyKey{}).(*contextKey); ok && k.key != "" {
+		fingerprint, err := requestFingerprint(req)
+		if err != nil {
+			return "", err
+		}
+		if err := k.bind(fingerprint); err != nil {
+			return "", err
+		}
+		return k.key, nil
+	}
+	if c.Config.DisableIdempotencyKeys {
+		return "", nil
+	}
+	return NewIdempotencyKey()
+}
+
+// requestFingerprint identifies a request by its method, URL and body.
+func requestFingerprint(req *http.Request) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%v %v\n", req.Method, req.URL)
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NewIdempotencyKey returns a new random idempotency key, a version 4 UUID.
+func NewIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("pangea: cannot generate idempotency key: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
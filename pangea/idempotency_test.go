@@ -0,0 +1,126 @@
+package pangea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+// keysHandler records the idempotency keys of the requests, failing the first one with a 503.
+func keysHandler(keys *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*keys = append(*keys, r.Header.Get(pangea.IdempotencyKeyHeader))
+		if len(*keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"request_id": "some-id", "status_code": 503, "status": "ServiceNotAvailable", "result": null}`)
+			return
+		}
+		okHandler(w, r)
+	}
+}
+
+func TestDo_Retries_NonIdempotent_Requests_With_Generated_Idempotency_Key(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	var keys []string
+	mux.HandleFunc("/v1/log", keysHandler(&keys))
+
+	client := retryClient(t, url)
+	client.NonIdempotentPaths = []string{"v1/log"}
+
+	req, _ := client.NewRequest("POST", "v1/log", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, resp.Attempts)
+	assert.Len(t, keys, 2)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], resp.IdempotencyKey)
+
+	req, _ = client.NewRequest("POST", "v1/log", nil)
+	resp, err = client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, keys[0], resp.IdempotencyKey)
+}
+
+func TestDo_Sends_Idempotency_Key_Of_Context(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	var keys []string
+	mux.HandleFunc("/test", keysHandler(&keys))
+
+	client := retryClient(t, url)
+	client.NonIdempotentPaths = []string{"test"}
+	ctx := pangea.WithIdempotencyKey(context.Background(), "some-key")
+	req, _ := client.NewRequest("POST", "test", nil)
+	resp, err := client.Do(ctx, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"some-key", "some-key"}, keys)
+	assert.Equal(t, "some-key", resp.IdempotencyKey)
+
+	req, _ = client.NewRequest("POST", "test", nil)
+	resp, err = client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "some-key", keys[2])
+	assert.Equal(t, keys[2], resp.IdempotencyKey)
+}
+
+func TestDo_Sends_Idempotency_Key_Of_Context_Only_With_Writes(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	var keys []string
+	record := func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(pangea.IdempotencyKeyHeader))
+		okHandler(w, r)
+	}
+	mux.HandleFunc("/read", record)
+	mux.HandleFunc("/write", record)
+
+	client := pangea.NewClient("service", pangeatesting.TestConfig(url))
+	client.NonIdempotentPaths = []string{"write"}
+	ctx := pangea.WithIdempotencyKey(context.Background(), "some-key")
+
+	// The read is sent without the key and does not bind it.
+	req, _ := client.NewRequest("POST", "read", map[string]string{"query": "first"})
+	resp, err := client.Do(ctx, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", resp.IdempotencyKey)
+
+	req, _ = client.NewRequest("POST", "write", map[string]string{"message": "first"})
+	resp, err = client.Do(ctx, req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "some-key", resp.IdempotencyKey)
+	assert.Equal(t, []string{"", "some-key"}, keys)
+}
+
+func TestDo_Sends_Idempotency_Key_Of_Context_With_One_Request(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	var keys []string
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(pangea.IdempotencyKeyHeader))
+		okHandler(w, r)
+	})
+
+	client := pangea.NewClient("service", pangeatesting.TestConfig(url))
+	client.NonIdempotentPaths = []string{"test"}
+	ctx := pangea.WithIdempotencyKey(context.Background(), "some-key")
+	req, _ := client.NewRequest("POST", "test", map[string]string{"message": "first"})
+	_, err := client.Do(ctx, req, nil)
+	assert.NoError(t, err)
+
+	// The same request can be sent again with the key.
+	req, _ = client.NewRequest("POST", "test", map[string]string{"message": "first"})
+	_, err = client.Do(ctx, req, nil)
+	assert.NoError(t, err)
+
+	req, _ = client.NewRequest("POST", "test", map[string]string{"message": "second"})
+	_, err = client.Do(ctx, req, nil)
+	assert.ErrorIs(t, err, pangea.ErrIdempotencyKeyReused)
+	assert.Equal(t, []string{"some-key", "some-key"}, keys)
+}
@@ -0,0 +1,154 @@
+package pangea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maskedValue = "****"
+
+// loggingMiddleware logs every request and its response with logger at level.
+// The bodies are only logged if logBodies is set, as they can carry sensitive data.
+func loggingMiddleware(logger *slog.Logger, level slog.Level, logBodies bool) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			if !logger.Enabled(ctx, level) {
+				return next(ctx, req)
+			}
+
+			attrs := []slog.Attr{
+				slog.String("service", req.ServiceName),
+				slog.String("method", req.HTTPRequest.Method),
+				slog.String("url", req.HTTPRequest.URL.String()),
+				headersAttr(req.HTTPRequest.Header),
+			}
+			if logBodies {
+				if body, ok := requestBody(req.HTTPRequest); ok {
+					attrs = append(attrs, slog.String("body", body))
+				}
+			}
+			logger.LogAttrs(ctx, level, "pangea request", attrs...)
+
+			start := time.Now()
+			resp, err := next(ctx, req)
+
+			attrs = []slog.Attr{
+				slog.String("service", req.ServiceName),
+				slog.String("method", req.HTTPRequest.Method),
+				slog.String("url", req.HTTPRequest.URL.String()),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if resp != nil {
+				attrs = append(attrs,
+					slog.Int("http_status", resp.HTTPResponse.StatusCode),
+					slog.String("request_id", StringValue(resp.RequestID)),
+					slog.Int("status_code", IntValue(resp.StatusCode)),
+					slog.String("status", StringValue(resp.Status)),
+					slog.String("summary", StringValue(resp.Summary)),
+					headersAttr(resp.HTTPResponse.Header),
+				)
+				if logBodies && len(resp.RawResult) > 0 {
+					attrs = append(attrs, slog.String("result", string(resp.RawResult)))
+				}
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", errorMessage(err, logBodies)))
+			}
+			logger.LogAttrs(ctx, level, "pangea response", attrs...)
+			return resp, err
+		}
+	}
+}
+
+// errorMessage returns the message of err to log. The messages of the API errors have the
+// bodies of the responses, they are replaced by their status, request ID and summary unless
+// logBodies is set.
+func errorMessage(err error, logBodies bool) string {
+	if logBodies {
+		return err.Error()
+	}
+	var unmarshalErr *UnMarshalError
+	if errors.As(err, &unmarshalErr) {
+		return "pangea: failed to unmarshall body: " + apiErrorMessage(&unmarshalErr.APIError)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErrorMessage(apiErr)
+	}
+	return err.Error()
+}
+
+// apiErrorMessage returns the message of err without the body of the response.
+func apiErrorMessage(err *APIError) string {
+	b := new(strings.Builder)
+	if err.HTTPResponse != nil {
+		fmt.Fprintf(b, "pangea: %v %v: ", err.HTTPResponse.Request.Method, err.HTTPResponse.Request.URL)
+	}
+	switch {
+	case err.ResponseHeader != nil:
+		h := err.ResponseHeader
+		fmt.Fprintf(b, "status: %v, request_id: %v, summary: %v",
+			StringValue(h.Status), StringValue(h.RequestID), StringValue(h.Summary))
+	case err.HTTPResponse != nil:
+		fmt.Fprintf(b, "%v", err.HTTPResponse.StatusCode)
+	case err.Err != nil:
+		// The request failed before a response was received, e.g. a connection error.
+		b.WriteString(err.Err.Error())
+	}
+	return b.String()
+}
+
+// headersAttr groups the headers in an attribute, masking the secrets they carry.
+func headersAttr(h http.Header) slog.Attr {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		attrs = append(attrs, slog.String(name, maskHeader(name, strings.Join(h[name], ", "))))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// maskHeader masks the value of the Authorization and config ID headers.
+// The scheme of the Authorization header is kept.
+func maskHeader(name, value string) string {
+	name = strings.ToLower(name)
+	switch {
+	case name == "authorization":
+		if scheme, _, ok := strings.Cut(value, " "); ok {
+			return scheme + " " + maskedValue
+		}
+		return maskedValue
+	case strings.HasPrefix(name, "x-pangea-") && strings.HasSuffix(name, "-config-id"):
+		return maskedValue
+	}
+	return value
+}
+
+// requestBody returns the body of req without consuming it, if it can be read again.
+func requestBody(req *http.Request) (string, bool) {
+	if req.Body == nil || req.GetBody == nil {
+		return "", false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", false
+	}
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(string(b), "\n"), true
+}
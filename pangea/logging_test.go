@@ -0,0 +1,148 @@
+package pangea_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeLogLines(t *testing.T, b *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	dec := json.NewDecoder(b)
+	for dec.More() {
+		var line map[string]any
+		if !assert.NoError(t, dec.Decode(&line)) {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestDo_With_Logger_Logs_Request_And_Response(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 200, "status": "Success", "summary": "done", "result": {"key": "value"}}`)
+	})
+
+	var buf bytes.Buffer
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CfgToken = "pci_secret"
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cfg.LogBodies = true
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", map[string]string{"message": "hello"})
+	_, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.NotContains(t, out, "TestToken")
+	assert.NotContains(t, out, "pci_secret")
+
+	lines := decodeLogLines(t, &buf)
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+
+	request := lines[0]
+	assert.Equal(t, "DEBUG", request["level"])
+	assert.Equal(t, "pangea request", request["msg"])
+	assert.Equal(t, "POST", request["method"])
+	assert.Equal(t, `{"message":"hello"}`, request["body"])
+	headers := request["headers"].(map[string]any)
+	assert.Equal(t, "Bearer ****", headers["Authorization"])
+	assert.Equal(t, "****", headers["X-Pangea-Service-Config-Id"])
+	assert.Equal(t, "application/json", headers["Content-Type"])
+
+	response := lines[1]
+	assert.Equal(t, "pangea response", response["msg"])
+	assert.Equal(t, "some-id", response["request_id"])
+	assert.Equal(t, "Success", response["status"])
+	assert.Equal(t, "done", response["summary"])
+	assert.Equal(t, float64(200), response["http_status"])
+	assert.Equal(t, `{"key": "value"}`, response["result"])
+}
+
+func TestDo_With_Logger_Logs_Errors_Without_Bodies(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 400, "status": "ValidationError", "summary": "bad request", "result": {"errors": [{"detail": "secret-payload"}]}}`)
+	})
+
+	var buf bytes.Buffer
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	cfg.LogLevel = slog.LevelInfo
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", map[string]string{"message": "hello"})
+	_, err := client.Do(context.Background(), req, nil)
+	assert.Error(t, err)
+
+	lines := decodeLogLines(t, &buf)
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	assert.Equal(t, "INFO", lines[0]["level"])
+	assert.NotContains(t, lines[0], "body")
+	assert.NotContains(t, lines[1], "result")
+	assert.Equal(t, "ValidationError", lines[1]["status"])
+	assert.Contains(t, err.Error(), "secret-payload")
+	assert.NotContains(t, lines[1]["error"], "secret-payload")
+	assert.Contains(t, lines[1]["error"], "status: ValidationError, request_id: some-id, summary: bad request")
+}
+
+func TestDo_With_Logger_Logs_Unmarshal_Errors_Without_Bodies(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 200, "status": "Success", "result": {"secret-payload"`)
+	})
+
+	var buf bytes.Buffer
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	assert.ErrorContains(t, err, "secret-payload")
+
+	out := buf.String()
+	assert.NotContains(t, out, "secret-payload")
+	lines := decodeLogLines(t, &buf)
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+	assert.Contains(t, lines[1]["error"], "failed to unmarshall body")
+}
+
+func TestDo_With_Logger_Below_Level_Logs_Nothing(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", okHandler)
+
+	var buf bytes.Buffer
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
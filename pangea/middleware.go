@@ -0,0 +1,64 @@
+package pangea
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Request is a request to a Pangea service as seen by a Middleware.
+type Request struct {
+	// The identifier for the service
+	ServiceName string
+
+	// The path of the endpoint relative to the service URL, e.g. "v1/log"
+	Path string
+
+	// The HTTP request to be sent. Middlewares can modify it, e.g. to add headers.
+	HTTPRequest *http.Request
+}
+
+// Handler sends a Request to Pangea and returns the decoded response.
+//
+//	If the response envelope could be decoded it is returned along with the error,
+//	so the ResponseHeader of failed requests is available too.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler to add behavior around every request sent by a Client,
+// including the polls of accepted requests.
+type Middleware func(next Handler) Handler
+
+// chainMiddlewares returns a Handler that calls the middlewares in order, the first one
+// being the outermost, before calling h.
+func chainMiddlewares(h Handler, middlewares []Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+type requestPathKey struct{}
+
+// withRequestPath records the endpoint path used to build a request.
+func withRequestPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, requestPathKey{}, path)
+}
+
+// requestPath returns the endpoint path the request was built with by NewRequest,
+// falling back to the path of the URL for requests built elsewhere.
+func requestPath(req *http.Request) string {
+	if path, ok := req.Context().Value(requestPathKey{}).(string); ok {
+		return path
+	}
+	return strings.TrimPrefix(req.URL.Path, "/")
+}
+
+// RequestEndpoint returns the service and the endpoint path of a request sent by a Client,
+// e.g. "audit" and "v1/log". It can be used by the http.RoundTripper of the HTTP client,
+// as it is sent with the request context. The service is empty for other requests.
+func RequestEndpoint(req *http.Request) (service, path string) {
+	if state := requestStateFrom(req.Context()); state != nil {
+		return state.service, state.path
+	}
+	return "", requestPath(req)
+}
@@ -0,0 +1,98 @@
+package pangea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewares_Are_Called_In_Order_Around_Every_Request(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	mux.HandleFunc("/v1/test", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "first,second", r.Header.Get("X-Middleware"))
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{
+			"request_id": "some-id",
+			"status_code": 200,
+			"status": "Success",
+			"result": {"key": "value"},
+			"summary": "ok"
+		}`)
+	})
+
+	var calls []string
+	middleware := func(name string) pangea.Middleware {
+		return func(next pangea.Handler) pangea.Handler {
+			return func(ctx context.Context, req *pangea.Request) (*pangea.Response, error) {
+				calls = append(calls, name+":"+req.ServiceName+":"+req.Path)
+				if h := req.HTTPRequest.Header.Get("X-Middleware"); h != "" {
+					name = h + "," + name
+				}
+				req.HTTPRequest.Header.Set("X-Middleware", name)
+				resp, err := next(ctx, req)
+				calls = append(calls, name+":"+pangea.StringValue(resp.RequestID))
+				return resp, err
+			}
+		}
+	}
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Middlewares = []pangea.Middleware{middleware("first"), middleware("second")}
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "v1/test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"first:service:v1/test",
+		"second:service:v1/test",
+		"first,second:some-id",
+		"first:some-id",
+	}, calls)
+}
+
+func TestMiddlewares_See_Response_Header_And_Error_Of_Failed_Requests(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	mux.HandleFunc("/v1/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{
+			"request_id": "some-id",
+			"status_code": 400,
+			"status": "ValidationError",
+			"result": null,
+			"summary": "bad request"
+		}`)
+	})
+
+	var gotResp *pangea.Response
+	var gotErr error
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Middlewares = []pangea.Middleware{
+		func(next pangea.Handler) pangea.Handler {
+			return func(ctx context.Context, req *pangea.Request) (*pangea.Response, error) {
+				gotResp, gotErr = next(ctx, req)
+				return gotResp, gotErr
+			}
+		},
+	}
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "v1/test", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+	assert.Equal(t, err, gotErr)
+	assert.NotNil(t, gotResp)
+	assert.Equal(t, "ValidationError", pangea.StringValue(gotResp.Status))
+}
@@ -8,12 +8,13 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/pangeacyber/go-pangea/internal/defaults"
 )
 
@@ -29,17 +30,66 @@ type RetryConfig struct {
 	RetryMax     int           // Maximum number of retries
 }
 
+type PollConfig struct {
+	PollInterval    time.Duration // Time to wait before the first poll
+	PollIntervalMax time.Duration // Maximum time to wait between polls
+	MaxWait         time.Duration // Maximum total time to wait for the result, 0 means until the context expires
+	Backoff         float64       // Multiplier applied to the wait after each poll
+}
+
+var defaultPollConfig = PollConfig{
+	PollInterval:    1 * time.Second,
+	PollIntervalMax: 30 * time.Second,
+	Backoff:         2,
+}
+
 type Config struct {
 	// The Bearer token used to authenticate requests.
 	Token string
 
+	// The provider of the Bearer token used to authenticate requests.
+	// If set it takes precedence over Token.
+	TokenProvider TokenProvider
+
 	// The Config ID token of the service.
 	CfgToken string
 
 	// The HTTP client to be used by the client.
 	//  It defaults to defaults.HTTPClient
+	// The client is used as is: Retry, Transport and the TLS and proxy settings don't apply to it.
+	// It is kept by Copy and MergeIn, so it is used by the clients built from copies of the config.
 	HTTPClient *http.Client
 
+	// The transport of the HTTP client built by the client when HTTPClient is not set.
+	// It is wrapped by the retry client if Retry is set. It defaults to defaults.HTTPTransport
+	Transport http.RoundTripper
+
+	// PEM files of the certificate authorities trusted on top of the system ones, e.g. the CA
+	// of a proxy intercepting TLS. The TLS and proxy settings are not used if Transport or
+	// HTTPClient is set.
+	CABundleFiles []string
+
+	// PEM files of the client certificate and its private key, for mutual TLS
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// URL of the proxy to send the requests through, e.g. "http://proxy.internal:3128".
+	// It defaults to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables
+	ProxyURL string
+
+	// Minimum TLS version, e.g. tls.VersionTLS13, it defaults to TLS 1.2
+	TLSMinVersion uint16
+
+	// Set to true not to generate idempotency keys for the requests to the non-idempotent
+	// endpoints, e.g. "v1/log", which are then not retried. A key set with WithIdempotencyKey
+	// is still sent.
+	DisableIdempotencyKeys bool
+
+	// Set to keep the connections to Pangea alive between requests, with a pooled transport
+	// tuned by the config. The pool is per client, Transport can share one between clients.
+	// It is not used if Transport or HTTPClient is set.
+	PooledTransport *TransportConfig
+
 	// Base domain for API requests.
 	Domain string
 
@@ -49,6 +99,19 @@ type Config struct {
 	// Set to "local" for testing locally
 	Enviroment string
 
+	// Base URLs of the services by service name, overriding Domain, e.g.
+	// "https://gateway.internal/pangea/audit" for a service behind an API gateway.
+	ServiceURLs map[string]string
+
+	// Template of the base URL of the services overriding Domain, "{service}" being replaced
+	// by the service name, e.g. "https://{service}.aws.eu.pangea.cloud" or
+	// "https://gateway.internal/pangea/{service}".
+	URLTemplate string
+
+	// Resolver of the base URL of the services, taking precedence over ServiceURLs,
+	// URLTemplate and Domain.
+	EndpointResolver EndpointResolver
+
 	// AdditionalHeaders is a map of additional headers to be sent with the request.
 	AdditionalHeaders map[string]string
 
@@ -58,6 +121,37 @@ type Config struct {
 
 	// Retry config defaults to a base retry option
 	RetryConfig *RetryConfig
+
+	// if Do should poll the request/{id} endpoint when Pangea accepts a request (202)
+	// instead of returning an AcceptedError
+	PollAccepted bool
+
+	// Poll config defaults to a base poll option
+	PollConfig *PollConfig
+
+	// Middlewares wrap every request sent by the client, the first one being the outermost.
+	Middlewares []Middleware
+
+	// Client side rate limits by service name, e.g. "ip-intel".
+	// The limits are enforced per client, on all the requests it sends. The rate limit applies
+	// to every attempt, retries included, unless HTTPClient is set: the SDK then only sees the
+	// first attempt.
+	RateLimits map[string]*RateLimitConfig
+
+	// Circuit breaker config, the circuit breaker is disabled if nil.
+	// Each client keeps the state of the circuits of the hosts it sends requests to.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// Logger logs the requests and responses of the client, with their headers and
+	// Pangea response header. The bearer token and the config ID are masked.
+	Logger *slog.Logger
+
+	// The level requests and responses are logged at, defaults to slog.LevelDebug
+	LogLevel slog.Leveler
+
+	// Set to true to also log the request bodies and response results,
+	// which can contain sensitive data
+	LogBodies bool
 }
 
 // A Client manages communication with the Pangea API.
@@ -73,35 +167,69 @@ type Client struct {
 
 	// The identifier for the service
 	ServiceName string
+
+	// The paths of the endpoints that are not idempotent, e.g. "v1/log".
+	// Requests to these endpoints are only retried if they carry an idempotency key.
+	NonIdempotentPaths []string
+
+	limiter *rateLimiter
+	breaker *circuitBreaker
+
+	// endpoint is the base URL of the service
+	endpoint *url.URL
+
+	// err is the error found building the client from its config
+	err error
 }
 
+// NewClient returns a client of service with the merged configs. If the configs are invalid,
+// e.g. the endpoint of the service is not a valid URL or a CA bundle cannot be read, the error
+// is returned by Err and by every request.
 func NewClient(service string, baseCfg *Config, additionalConfigs ...*Config) *Client {
 	cfg := baseCfg.Copy()
 	cfg.MergeIn(additionalConfigs...)
-	cfg.HTTPClient = chooseHTTPClient(cfg)
+	endpoint, err := resolveEndpoint(cfg, service)
+	if err == nil {
+		cfg.HTTPClient, err = chooseHTTPClient(cfg)
+	}
 	return &Client{
+		endpoint:    endpoint,
+		err:         err,
 		ServiceName: service,
 		Token:       cfg.Token,
 		Config:      cfg,
 		UserAgent:   userAgent,
+		limiter:     newRateLimiter(cfg.RateLimits[service]),
+		breaker:     newCircuitBreaker(cfg.CircuitBreaker),
 	}
 }
 
-func chooseHTTPClient(cfg *Config) *http.Client {
+// Err returns the error found building the client from its config, if any.
+func (c *Client) Err() error {
+	return c.err
+}
+
+func chooseHTTPClient(cfg *Config) (*http.Client, error) {
 	if cfg.HTTPClient != nil {
-		return cfg.HTTPClient
+		return cfg.HTTPClient, nil
+	}
+	transport, err := newTransport(cfg, cfg.Retry)
+	if err != nil {
+		return nil, err
 	}
 	if cfg.Retry {
+		cli := defaults.RetryClient()
 		if cfg.RetryConfig != nil {
-			cli := retryablehttp.NewClient()
 			cli.RetryMax = cfg.RetryConfig.RetryMax
 			cli.RetryWaitMin = cfg.RetryConfig.RetryWaitMin
 			cli.RetryWaitMax = cfg.RetryConfig.RetryWaitMax
-			return cli.StandardClient()
 		}
-		return defaults.HTTPClientWithRetries()
+		cli.CheckRetry = retryPolicy
+		cli.Backoff = retryBackoff
+		cli.HTTPClient.Transport = &attemptTransport{base: transport}
+		return cli.StandardClient(), nil
 	}
-	return defaults.HTTPClient()
+	return &http.Client{Transport: &attemptTransport{base: transport}}, nil
 }
 
 func mergeHeaders(req *http.Request, additionalHeaders map[string]string) {
@@ -111,24 +239,17 @@ func mergeHeaders(req *http.Request, additionalHeaders map[string]string) {
 }
 
 func (c *Client) serviceUrl(service, path string) (string, error) {
-	cfg := c.Config
-	endpoint := ""
-
-	scheme := "https://"
-	if cfg.Insecure == true {
-		scheme = "http://"
+	base := c.endpoint
+	if base == nil || service != c.ServiceName {
+		var err error
+		if base, err = resolveEndpoint(c.Config, service); err != nil {
+			return "", err
+		}
 	}
 
 	// Remove slashes, just in case
 	path = strings.TrimPrefix(path, "/")
-	domain := strings.TrimSuffix(cfg.Domain, "/")
-
-	if cfg.Enviroment == "local" {
-		// If we are testing locally do not use service
-		endpoint = fmt.Sprintf("%s%s/%s", scheme, domain, path)
-	} else {
-		endpoint = fmt.Sprintf("%s%s.%s/%s", scheme, service, domain, path)
-	}
+	endpoint := strings.TrimSuffix(base.String(), "/") + "/" + path
 
 	u, err := url.Parse(endpoint)
 	if err != nil {
@@ -143,6 +264,9 @@ func (c *Client) serviceUrl(service, path string) (string, error) {
 // specified, the value pointed to by body is JSON encoded and included as the
 // request body.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
 	u, err := c.serviceUrl(c.ServiceName, urlStr)
 	if err != nil {
 		return nil, err
@@ -156,12 +280,17 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 		}
 	}
 
-	req, err := http.NewRequest(method, u, buf)
+	path := strings.TrimPrefix(urlStr, "/")
+	req, err := http.NewRequestWithContext(withRequestPath(context.Background(), path), method, u, buf)
 	if err != nil {
 		return nil, err
 	}
-	if c.Token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.Token))
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -176,6 +305,17 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	return req, nil
 }
 
+func (c *Client) token() (string, error) {
+	if c.Config.TokenProvider == nil {
+		return c.Token, nil
+	}
+	token, err := c.Config.TokenProvider.Token()
+	if err != nil {
+		return "", fmt.Errorf("pangea: cannot get token: %w", err)
+	}
+	return token, nil
+}
+
 type PangeaResponse[T any] struct {
 	Response
 	Result *T
@@ -196,6 +336,7 @@ func newResponse(r *http.Response) (*Response, error) {
 	if err := json.Unmarshal(data, response); err != nil {
 		return nil, NewUnMarshalError(err, data, r, nil)
 	}
+	response.RawBody = data
 	return response, nil
 }
 
@@ -204,6 +345,9 @@ func newResponse(r *http.Response) (*Response, error) {
 //	If an error or API Error occurs, the error will contain more information. Otherwise you
 //	are supposed to read and close the response's Body.
 func (c *Client) BareDo(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
 	resp, err := c.Config.HTTPClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, NewAPIError(err, resp, nil)
@@ -214,8 +358,8 @@ func (c *Client) BareDo(ctx context.Context, req *http.Request) (*http.Response,
 // Do sends an API request and returns the API response. The API response is
 // JSON decoded and stored in the value pointed to by v, or returned as an
 // error if an API error has occurred. If v is nil, and no error hapens, the response is returned as is.
-// The provided ctx must be non-nil, if it is nil an error is returned. If it
-// is canceled or times out, ctx.Err() will be returned.
+// If PollAccepted is set in the config and Pangea accepts the request (202), Do polls
+// for the result until it is ready, PollConfig.MaxWait is exceeded or ctx is done.
 //
 // The provided ctx must be non-nil, if it is nil an error is returned. If it is
 // canceled or times out, ctx.Err() will be returned.
@@ -223,8 +367,132 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v any) (*Response, e
 	if ctx == nil {
 		return nil, errNonNilContext
 	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	// The key is set once, so it is the same for all the attempts to send the request.
+	if req.Header.Get(IdempotencyKeyHeader) == "" {
+		key, err := c.idempotencyKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+	}
+
+	start := time.Now()
+	response, err := c.do(ctx, req)
+	if err != nil {
+		var acceptedErr *AcceptedError
+		if !c.Config.PollAccepted || !errors.As(err, &acceptedErr) {
+			return nil, err
+		}
+		response, err = c.pollAcceptedResponse(ctx, response, acceptedErr)
+		if err != nil {
+			return nil, err
+		}
+		response.Latency = time.Since(start)
+	}
+	response.IdempotencyKey = req.Header.Get(IdempotencyKeyHeader)
+
+	err = unmarshalResult(response, v)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// do sends a single API request through the middlewares and checks the response,
+// without decoding its result.
+func (c *Client) do(ctx context.Context, req *http.Request) (*Response, error) {
+	handler := chainMiddlewares(c.send, c.middlewares())
+	response, err := handler(ctx, &Request{
+		ServiceName: c.ServiceName,
+		Path:        requestPath(req),
+		HTTPRequest: req,
+	})
+	if err != nil && errors.Is(err, ErrUnauthorized) {
+		response, err = c.retryWithRefreshedToken(ctx, handler, req, err)
+	}
+	// The response is returned with the API errors, e.g. for the attempts of an accepted request.
+	return response, err
+}
+
+// middlewares returns the middlewares of the config, followed by the logging middleware
+// if a Logger is set, so the headers added by the other middlewares are logged.
+func (c *Client) middlewares() []Middleware {
+	if c.Config.Logger == nil {
+		return c.Config.Middlewares
+	}
+	level := slog.LevelDebug
+	if c.Config.LogLevel != nil {
+		level = c.Config.LogLevel.Level()
+	}
+	middlewares := make([]Middleware, 0, len(c.Config.Middlewares)+1)
+	middlewares = append(middlewares, c.Config.Middlewares...)
+	return append(middlewares, loggingMiddleware(c.Config.Logger, level, c.Config.LogBodies))
+}
+
+// retryWithRefreshedToken refreshes the token and sends the request again, if the
+// token provider supports refreshing. Otherwise it returns the original error.
+func (c *Client) retryWithRefreshedToken(ctx context.Context, handler Handler, req *http.Request, unauthorizedErr error) (*Response, error) {
+	refresher, ok := c.Config.TokenProvider.(TokenRefresher)
+	if !ok || (req.Body != nil && req.GetBody == nil) {
+		return nil, unauthorizedErr
+	}
+	err := refresher.Refresh()
+	if err != nil {
+		return nil, fmt.Errorf("pangea: cannot refresh token: %w", err)
+	}
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	return handler(ctx, &Request{
+		ServiceName: c.ServiceName,
+		Path:        requestPath(retry),
+		HTTPRequest: retry,
+	})
+}
+
+// send is the innermost Handler, it sends the request and decodes the response envelope.
+func (c *Client) send(ctx context.Context, req *Request) (*Response, error) {
+	state := &requestState{
+		service: req.ServiceName,
+		path:    req.Path,
+		retry:   c.isIdempotent(req.Path) || req.HTTPRequest.Header.Get(IdempotencyKeyHeader) != "",
+		limiter: c.limiter,
+	}
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	done, err := c.breaker.allow(req.HTTPRequest.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.sendRequest(ctx, state, req.HTTPRequest)
+	done(requestOutcome(ctx, response, err))
+	return response, err
+}
 
-	resp, err := c.BareDo(ctx, req)
+// sendRequest sends the request with the HTTP client and checks the decoded response envelope.
+func (c *Client) sendRequest(ctx context.Context, state *requestState, req *http.Request) (*Response, error) {
+	start := time.Now()
+	resp, err := c.BareDo(withRequestState(ctx, state), req)
 	if err != nil {
 		return nil, err
 	}
@@ -233,21 +501,109 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v any) (*Response, e
 	if err != nil {
 		return nil, err
 	}
+	response.Latency = time.Since(start)
+	response.RequestSize = req.ContentLength
+	response.ServerTimings = parseServerTimings(resp.Header)
+	response.Attempts = int(atomic.LoadInt32(&state.attempts))
+	response.AttemptTimings = state.attemptTimings()
+	if response.Attempts == 0 {
+		// The HTTP client was provided by the user, attempts cannot be counted.
+		response.Attempts = 1
+		response.AttemptTimings = []AttemptTiming{{Start: start, Duration: response.Latency, StatusCode: resp.StatusCode}}
+	}
 
 	err = CheckResponse(response)
 	if err != nil {
-		return nil, err
+		return response, err
 	}
+	return response, nil
+}
 
+func (c *Client) isIdempotent(path string) bool {
+	for _, p := range c.NonIdempotentPaths {
+		if p == path {
+			return false
+		}
+	}
+	return true
+}
+
+func unmarshalResult(response *Response, v any) error {
 	switch v := v.(type) {
 	case nil:
 	default:
-		err = response.UnMarshalResult(v)
+		err := response.UnMarshalResult(v)
 		if err != nil {
-			return nil, NewUnMarshalError(err, response.RawResult, response.HTTPResponse, &response.ResponseHeader)
+			return NewUnMarshalError(err, response.RawResult, response.HTTPResponse, &response.ResponseHeader)
 		}
 	}
-	return response, nil
+	return nil
+}
+
+// pollAcceptedResponse fetches the result of an accepted request until it is ready.
+// If PollConfig.MaxWait is exceeded the last AcceptedError is returned, so the caller
+// can keep fetching the result later. The response has the attempts of the accepted request
+// and of all the polls.
+func (c *Client) pollAcceptedResponse(ctx context.Context, accepted *Response, acceptedErr *AcceptedError) (*Response, error) {
+	var attempts int
+	var timings []AttemptTiming
+	if accepted != nil {
+		attempts, timings = accepted.Attempts, accepted.AttemptTimings
+	}
+
+	cfg := c.pollConfig()
+	var deadline time.Time
+	if cfg.MaxWait > 0 {
+		deadline = time.Now().Add(cfg.MaxWait)
+	}
+
+	wait := cfg.PollInterval
+	for {
+		if !deadline.IsZero() && time.Now().Add(wait).After(deadline) {
+			return nil, acceptedErr
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		response, err := c.fetchAcceptedResponse(ctx, acceptedErr.ReqID())
+		if response != nil {
+			attempts += response.Attempts
+			timings = append(timings, response.AttemptTimings...)
+		}
+		if !errors.As(err, &acceptedErr) {
+			if err != nil {
+				return nil, err
+			}
+			response.Attempts = attempts
+			response.AttemptTimings = timings
+			if accepted != nil {
+				response.RequestSize = accepted.RequestSize
+			}
+			return response, nil
+		}
+
+		wait = time.Duration(float64(wait) * cfg.Backoff)
+		if cfg.PollIntervalMax > 0 && wait > cfg.PollIntervalMax {
+			wait = cfg.PollIntervalMax
+		}
+	}
+}
+
+func (c *Client) pollConfig() PollConfig {
+	cfg := defaultPollConfig
+	if c.Config.PollConfig != nil {
+		cfg = *c.Config.PollConfig
+	}
+	if cfg.Backoff < 1 {
+		cfg.Backoff = 1
+	}
+	return cfg
 }
 
 func CheckResponse(r *Response) error {
@@ -257,11 +613,21 @@ func CheckResponse(r *Response) error {
 	if r.HTTPResponse.StatusCode <= http.StatusOK {
 		return nil
 	}
-	return &APIError{
+	apiErr := APIError{
 		HTTPResponse:   r.HTTPResponse,
 		ResponseHeader: &r.ResponseHeader,
 		Result:         r.RawResult,
 	}
+	if StringValue(r.Status) == "ValidationError" {
+		return newValidationError(apiErr)
+	}
+	if apiErr.Is(ErrRateLimited) {
+		return &RateLimitedError{
+			APIError:   apiErr,
+			RetryAfter: retryAfter(r.HTTPResponse.Header),
+		}
+	}
+	return &apiErr
 }
 
 func configHeaderName(key string) string {
@@ -284,10 +650,52 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.Token = other.Token
 	}
 
+	if other.TokenProvider != nil {
+		dst.TokenProvider = other.TokenProvider
+	}
+
 	if other.CfgToken != "" {
 		dst.CfgToken = other.CfgToken
 	}
 
+	// HTTPClient used to be dropped by Copy and MergeIn, so it was ignored by the service
+	// clients, which copy their config.
+	if other.HTTPClient != nil {
+		dst.HTTPClient = other.HTTPClient
+	}
+
+	if other.Transport != nil {
+		dst.Transport = other.Transport
+	}
+
+	if len(other.CABundleFiles) > 0 {
+		dst.CABundleFiles = other.CABundleFiles
+	}
+
+	if other.ClientCertFile != "" {
+		dst.ClientCertFile = other.ClientCertFile
+	}
+
+	if other.ClientKeyFile != "" {
+		dst.ClientKeyFile = other.ClientKeyFile
+	}
+
+	if other.ProxyURL != "" {
+		dst.ProxyURL = other.ProxyURL
+	}
+
+	if other.TLSMinVersion != 0 {
+		dst.TLSMinVersion = other.TLSMinVersion
+	}
+
+	if other.DisableIdempotencyKeys {
+		dst.DisableIdempotencyKeys = other.DisableIdempotencyKeys
+	}
+
+	if other.PooledTransport != nil {
+		dst.PooledTransport = other.PooledTransport
+	}
+
 	if other.Domain != "" {
 		dst.Domain = other.Domain
 	}
@@ -296,6 +704,18 @@ func mergeInConfig(dst *Config, other *Config) {
 		dst.Enviroment = other.Enviroment
 	}
 
+	if len(other.ServiceURLs) > 0 {
+		dst.ServiceURLs = other.ServiceURLs
+	}
+
+	if other.URLTemplate != "" {
+		dst.URLTemplate = other.URLTemplate
+	}
+
+	if other.EndpointResolver != nil {
+		dst.EndpointResolver = other.EndpointResolver
+	}
+
 	dst.Insecure = other.Insecure
 
 	if other.AdditionalHeaders != nil {
@@ -309,6 +729,38 @@ func mergeInConfig(dst *Config, other *Config) {
 	if other.RetryConfig != nil {
 		dst.RetryConfig = other.RetryConfig
 	}
+
+	if other.PollAccepted {
+		dst.PollAccepted = other.PollAccepted
+	}
+
+	if other.PollConfig != nil {
+		dst.PollConfig = other.PollConfig
+	}
+
+	if other.Middlewares != nil {
+		dst.Middlewares = other.Middlewares
+	}
+
+	if other.RateLimits != nil {
+		dst.RateLimits = other.RateLimits
+	}
+
+	if other.CircuitBreaker != nil {
+		dst.CircuitBreaker = other.CircuitBreaker
+	}
+
+	if other.Logger != nil {
+		dst.Logger = other.Logger
+	}
+
+	if other.LogLevel != nil {
+		dst.LogLevel = other.LogLevel
+	}
+
+	if other.LogBodies {
+		dst.LogBodies = other.LogBodies
+	}
 }
 
 // Copy will return a shallow copy of the Config object. If any additional
@@ -324,15 +776,27 @@ func (c *Config) Copy(cfgs ...*Config) *Config {
 	return dst
 }
 
-// FetchAcceptedResponse retries the
+// FetchAcceptedResponse retries the result of a request accepted by Pangea.
+// If the result is not ready yet an AcceptedError is returned.
 func (c *Client) FetchAcceptedResponse(ctx context.Context, reqID string, v interface{}) (*Response, error) {
-	req, err := c.NewRequest("GET", fmt.Sprintf("request/%v", reqID), nil)
+	if ctx == nil {
+		return nil, errNonNilContext
+	}
+	resp, err := c.fetchAcceptedResponse(ctx, reqID)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.Do(ctx, req, v)
+	err = unmarshalResult(resp, v)
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
+
+func (c *Client) fetchAcceptedResponse(ctx context.Context, reqID string) (*Response, error) {
+	req, err := c.NewRequest("GET", fmt.Sprintf("request/%v", reqID), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, req)
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/pangeacyber/go-pangea/pangea"
 	"github.com/stretchr/testify/assert"
@@ -425,3 +426,132 @@ func TestFetchAcceptedResponse_When_Server_Returns_200_It_JSON_Marshals_Payload(
 	assert.NotNil(t, payload.Key)
 	assert.Equal(t, "value", *payload.Key)
 }
+
+func TestDo_When_PollAccepted_Is_Set_It_Polls_Until_Result_Is_Ready(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.PollAccepted = true
+	cfg.PollConfig = &pangea.PollConfig{
+		PollInterval: time.Millisecond,
+		Backoff:      1,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{
+			"request_id": "some-id",
+			"request_time": "1970-01-01T00:00:00Z",
+			"response_time": "1970-01-01T00:00:10Z",
+			"status_code": 202,
+			"status": "Accepted",
+			"result": null,
+			"summary": "Accepted"
+		}`)
+	})
+
+	var polls int
+	mux.HandleFunc("/request/some-id", func(w http.ResponseWriter, r *http.Request) {
+		pangeatesting.TestMethod(t, r, "GET")
+		polls++
+		if polls < 3 {
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{
+				"request_id": "some-id",
+				"status_code": 202,
+				"status": "Accepted",
+				"result": null,
+				"summary": "Accepted"
+			}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{
+			"request_id": "some-id",
+			"status_code": 200,
+			"status": "Success",
+			"result": {"key": "value"},
+			"summary": "ok"
+		}`)
+	})
+
+	req, _ := client.NewRequest("POST", "test", nil)
+	body := &struct {
+		Key *string `json:"key"`
+	}{}
+	resp, err := client.Do(context.Background(), req, body)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, http.StatusOK, pangea.IntValue(resp.StatusCode))
+	assert.Equal(t, "value", pangea.StringValue(body.Key))
+
+	// The metrics are of the request and all the polls.
+	assert.Equal(t, 4, resp.Attempts)
+	assert.Len(t, resp.AttemptTimings, 4)
+	assert.Equal(t, http.StatusAccepted, resp.AttemptTimings[0].StatusCode)
+	assert.Equal(t, http.StatusOK, resp.AttemptTimings[3].StatusCode)
+	first, last := resp.AttemptTimings[0], resp.AttemptTimings[3]
+	assert.GreaterOrEqual(t, resp.Latency, last.Start.Add(last.Duration).Sub(first.Start))
+}
+
+func TestDo_When_PollAccepted_MaxWait_Is_Exceeded_It_Returns_AcceptedError(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.PollAccepted = true
+	cfg.PollConfig = &pangea.PollConfig{
+		PollInterval: time.Millisecond,
+		MaxWait:      20 * time.Millisecond,
+		Backoff:      2,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	accepted := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{
+			"request_id": "some-id",
+			"status_code": 202,
+			"status": "Accepted",
+			"result": null,
+			"summary": "Accepted"
+		}`)
+	}
+	mux.HandleFunc("/test", accepted)
+	mux.HandleFunc("/request/some-id", accepted)
+
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+
+	var acceptedErr *pangea.AcceptedError
+	assert.ErrorAs(t, err, &acceptedErr)
+	assert.Equal(t, "some-id", acceptedErr.ReqID())
+}
+
+func TestDo_When_PollAccepted_Context_Is_Canceled_It_Returns_Context_Error(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.PollAccepted = true
+	cfg.PollConfig = &pangea.PollConfig{
+		PollInterval: time.Hour,
+	}
+	client := pangea.NewClient("service", cfg)
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 202, "status": "Accepted", "result": null}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(ctx, req, nil)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,322 @@
+package pangeafake
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeautil"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/hash"
+	"github.com/pangeacyber/go-pangea/service/audit"
+)
+
+// TreeName is the name of the Merkle tree of the fake audit log.
+const TreeName = "pangeafake"
+
+const (
+	defaultSearchLimit = 20
+	searchResultsTTL   = 24 * time.Hour
+)
+
+type auditLog struct {
+	records  []*auditRecord
+	tree     merkleTree
+	searches map[string]*searchResults
+}
+
+type auditRecord struct {
+	envelope   audit.EventEnvelope
+	hash       hash.Hash
+	canonical  []byte
+	receivedAt time.Time
+}
+
+// searchResults are the records matching a search, along with the size of the tree
+// at the time of the search, which their proofs are relative to.
+type searchResults struct {
+	leaves    []int
+	treeSize  int
+	expiresAt time.Time
+}
+
+func newAuditLog() auditLog {
+	return auditLog{searches: map[string]*searchResults{}}
+}
+
+// Events returns the envelopes of the events logged so far, in order.
+func (s *Server) Events() []audit.EventEnvelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]audit.EventEnvelope, 0, len(s.audit.records))
+	for _, rec := range s.audit.records {
+		events = append(events, rec.envelope)
+	}
+	return events
+}
+
+// Roots returns the roots of the audit log tree of the given sizes, as published roots.
+// It implements audit.RootsProvider, to verify consistency proofs without Arweave.
+func (s *Server) Roots(ctx context.Context, treeSizes []string) (map[int]audit.Root, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	roots := make(map[int]audit.Root, len(treeSizes))
+	for _, v := range treeSizes {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("pangeafake: invalid tree size %q: %w", v, err)
+		}
+		if size < 1 || size > s.audit.tree.size() {
+			continue
+		}
+		roots[size] = *s.root(size)
+	}
+	return roots, nil
+}
+
+// root returns the root of the tree of size leaves, s.mu must be held.
+func (s *Server) root(size int) *audit.Root {
+	root := &audit.Root{
+		TreeName:    pangea.String(TreeName),
+		Size:        pangea.Int(size),
+		RootHash:    pangea.String(s.audit.tree.root(size).String()),
+		PublishedAt: pangea.Time(time.Now().UTC()),
+	}
+	if size > 1 {
+		root.ConsistencyProof = s.audit.tree.consistencyProof(size-1, size)
+	}
+	return root
+}
+
+func (s *Server) handleLog(w http.ResponseWriter, r *http.Request) {
+	var input audit.LogInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	if input.Event == nil {
+		s.writeValidationError(w, missingProperty("/event"))
+		return
+	}
+	if input.Event.Message == nil {
+		s.writeValidationError(w, missingProperty("/event/message"))
+		return
+	}
+
+	receivedAt := time.Now().UTC()
+	rec := &auditRecord{
+		envelope: audit.EventEnvelope{
+			Event:      input.Event,
+			Signature:  input.Signature,
+			PublicKey:  input.PublicKey,
+			ReceivedAt: pangea.String(receivedAt.Format(time.RFC3339Nano)),
+		},
+		receivedAt: receivedAt,
+	}
+	// The hash is checked by audit.SearchEvent.VerifyHash against the same canonical form.
+	rec.canonical, _ = pangeautil.CanonicalizeJSONMarshall(rec.envelope)
+	rec.hash = hash.Encode(rec.canonical)
+
+	s.mu.Lock()
+	s.audit.records = append(s.audit.records, rec)
+	s.audit.tree.add(rec.hash)
+	s.mu.Unlock()
+
+	out := &audit.LogOutput{
+		EventEnvelope: &rec.envelope,
+	}
+	if pangea.BoolValue(input.ReturnHash) || pangea.BoolValue(input.Verbose) {
+		out.Hash = pangea.String(rec.hash.String())
+	}
+	if pangea.BoolValue(input.Verbose) {
+		out.CanonicalEventBase64 = pangea.String(base64.StdEncoding.EncodeToString(rec.canonical))
+	}
+	s.writeResult(w, "Logged 1 record(s)", out)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var input audit.SearchInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	if input.Query == nil {
+		s.writeValidationError(w, missingProperty("/query"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := &searchResults{
+		treeSize:  s.audit.tree.size(),
+		expiresAt: time.Now().UTC().Add(searchResultsTTL),
+	}
+	for i, rec := range s.audit.records {
+		if matchesSearch(rec, &input) {
+			results.leaves = append(results.leaves, i)
+		}
+	}
+	if pangea.StringValue(input.Order) != "asc" {
+		sort.Sort(sort.Reverse(sort.IntSlice(results.leaves)))
+	}
+	if max := pangea.IntValue(input.MaxResults); max > 0 && len(results.leaves) > max {
+		results.leaves = results.leaves[:max]
+	}
+
+	id := fmt.Sprintf("pas_fake%08d", len(s.audit.searches)+1)
+	s.audit.searches[id] = results
+
+	out := &audit.SearchOutput{
+		ID:        pangea.String(id),
+		ExpiresAt: pangea.Time(results.expiresAt),
+		Count:     pangea.Int(len(results.leaves)),
+		Events:    s.searchEvents(results, 0, input.Limit, input.IncludeHash, input.IncludeMembershipProof),
+	}
+	if pangea.BoolValue(input.IncludeRoot) && results.treeSize > 0 {
+		out.Root = s.root(results.treeSize)
+	}
+	s.writeResult(w, fmt.Sprintf("Found %v event(s)", len(results.leaves)), out)
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	var input audit.SearchResultInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	if input.ID == nil {
+		s.writeValidationError(w, missingProperty("/id"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results, ok := s.audit.searches[pangea.StringValue(input.ID)]
+	if !ok || time.Now().After(results.expiresAt) {
+		s.write(w, http.StatusBadRequest, "SearchExpired", "The search results have expired or do not exist", nil)
+		return
+	}
+	out := &audit.SearchResultOutput{
+		Count:  pangea.Int(len(results.leaves)),
+		Events: s.searchEvents(results, pangea.IntValue(input.Offset), input.Limit, input.IncludeHash, input.IncludeMembershipProof),
+	}
+	if pangea.BoolValue(input.IncludeRoot) && results.treeSize > 0 {
+		out.Root = s.root(results.treeSize)
+	}
+	s.writeResult(w, fmt.Sprintf("Found %v event(s)", len(out.Events)), out)
+}
+
+func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
+	var input audit.RootInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	size := s.audit.tree.size()
+	if input.TreeSize != nil {
+		size = *input.TreeSize
+	}
+	if size < 1 || size > s.audit.tree.size() {
+		s.writeValidationError(w, fieldError{
+			Code:   "OutOfRange",
+			Detail: fmt.Sprintf("tree size %v is out of range, the tree has %v record(s)", size, s.audit.tree.size()),
+			Source: "/tree_size",
+		})
+		return
+	}
+	s.writeResult(w, "Root retrieved", &audit.RootOutput{Data: s.root(size)})
+}
+
+// searchEvents returns a page of the results, s.mu must be held.
+func (s *Server) searchEvents(results *searchResults, offset int, limit *int, includeHash, includeProof *bool) audit.SearchEvents {
+	n := defaultSearchLimit
+	if limit != nil {
+		n = *limit
+	}
+	if offset < 0 || offset > len(results.leaves) {
+		offset = len(results.leaves)
+	}
+	end := offset + n
+	if end > len(results.leaves) {
+		end = len(results.leaves)
+	}
+
+	events := make(audit.SearchEvents, 0, end-offset)
+	for _, leaf := range results.leaves[offset:end] {
+		rec := s.audit.records[leaf]
+		// The leaf index is the size of the tree once the record was added, as expected by
+		// audit.VerifyConsistencyProof, which checks the roots of that size and the previous one.
+		event := &audit.SearchEvent{
+			EventEnvelope: rec.envelope,
+			LeafIndex:     pangea.Int(leaf + 1),
+		}
+		if pangea.BoolValue(includeHash) {
+			event.Hash = pangea.String(rec.hash.String())
+		}
+		if pangea.BoolValue(includeProof) {
+			event.MembershipProof = pangea.String(s.audit.tree.membershipProof(leaf, results.treeSize))
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// matchesSearch returns whether the record matches the search. The query is a list of
+// keywords, matched against the message, and of <field>:<value> qualifiers, all matched
+// as case insensitive substrings.
+func matchesSearch(rec *auditRecord, input *audit.SearchInput) bool {
+	if input.Start != nil && rec.receivedAt.Before(*input.Start) {
+		return false
+	}
+	if input.End != nil && rec.receivedAt.After(*input.End) {
+		return false
+	}
+
+	event := rec.envelope.Event
+	fields := map[string]*string{
+		"actor":   event.Actor,
+		"action":  event.Action,
+		"message": event.Message,
+		"new":     event.New,
+		"old":     event.Old,
+		"source":  event.Source,
+		"status":  event.Status,
+		"target":  event.Target,
+	}
+	if restriction := input.SearchRestriction; restriction != nil {
+		if !restricted(event.Actor, restriction.Actor) || !restricted(event.Source, restriction.Source) ||
+			!restricted(event.Target, restriction.Target) {
+			return false
+		}
+	}
+
+	for _, term := range strings.Fields(pangea.StringValue(input.Query)) {
+		field, value, ok := strings.Cut(term, ":")
+		if _, known := fields[field]; !ok || !known {
+			field, value = "message", term
+		}
+		if !strings.Contains(strings.ToLower(pangea.StringValue(fields[field])), strings.ToLower(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func restricted(v *string, allowed []*string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if pangea.StringValue(a) == pangea.StringValue(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package pangeafake_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func logEvents(t *testing.T, client *audit.Audit, n int) {
+	for i := 0; i < n; i++ {
+		_, err := client.Log(context.Background(), &audit.LogInput{
+			Event: &audit.Event{
+				Actor:   pangea.String(fmt.Sprintf("actor-%v", i%2)),
+				Message: pangea.String(fmt.Sprintf("message %v", i)),
+			},
+			ReturnHash: pangea.Bool(true),
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestAudit_Search_Returns_Verifiable_Proofs(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	client, _ := audit.New(srv.Config(), audit.WithLogProofVerificationEnabled())
+	logEvents(t, client, 13)
+
+	resp, err := client.Search(context.Background(), &audit.SearchInput{
+		Query:                  pangea.String("message"),
+		IncludeHash:            pangea.Bool(true),
+		IncludeMembershipProof: pangea.Bool(true),
+		IncludeRoot:            pangea.Bool(true),
+		Limit:                  pangea.Int(100),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 13, pangea.IntValue(resp.Result.Count))
+	assert.Equal(t, 13, pangea.IntValue(resp.Result.Root.Size))
+	assert.Equal(t, "message 12", pangea.StringValue(resp.Result.Events[0].EventEnvelope.Event.Message))
+
+	validated, err := audit.VerifyAuditRecords(context.Background(), srv, resp.Result.Root, resp.Result.Events, true)
+	assert.NoError(t, err)
+	assert.Len(t, validated, 13)
+	for _, v := range validated {
+		message := pangea.StringValue(v.Event.Event.Message)
+		assert.True(t, pangea.BoolValue(v.MembershipProofStatus), message)
+		// The first record has no previous root to be consistent with.
+		assert.Equal(t, message != "message 0", pangea.BoolValue(v.ConsistencyProofStatus), message)
+	}
+}
+
+func TestAudit_Roots_Are_Consistent(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	client, _ := audit.New(srv.Config())
+	logEvents(t, client, 33)
+
+	sizes := make([]string, 0, 33)
+	for size := 1; size <= 33; size++ {
+		sizes = append(sizes, strconv.Itoa(size))
+	}
+	roots, err := srv.Roots(context.Background(), sizes)
+	assert.NoError(t, err)
+	assert.Len(t, roots, 33)
+
+	for idx := 2; idx <= 33; idx++ {
+		event := audit.SearchEvent{LeafIndex: pangea.Int(idx)}
+		assert.True(t, audit.VerifyConsistencyProof(roots, event, true), "tree size %v", idx)
+	}
+
+	resp, err := client.Root(context.Background(), &audit.RootInput{TreeSize: pangea.Int(20)})
+	assert.NoError(t, err)
+	assert.Equal(t, roots[20].RootHash, resp.Result.Data.RootHash)
+	assert.Equal(t, roots[20].ConsistencyProof, resp.Result.Data.ConsistencyProof)
+}
+
+func TestAudit_SearchResults_Pages_Through_Results(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	client, _ := audit.New(srv.Config())
+	logEvents(t, client, 5)
+
+	resp, err := client.Search(context.Background(), &audit.SearchInput{
+		Query: pangea.String("actor:actor-0"),
+		Order: pangea.String("asc"),
+		Limit: pangea.Int(2),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pangea.IntValue(resp.Result.Count))
+	assert.Len(t, resp.Result.Events, 2)
+
+	results, err := client.SearchResults(context.Background(), &audit.SearchResultInput{
+		ID:     resp.Result.ID,
+		Offset: pangea.Int(2),
+		Limit:  pangea.Int(2),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results.Result.Events, 1)
+	assert.Equal(t, "message 4", pangea.StringValue(results.Result.Events[0].EventEnvelope.Event.Message))
+}
+
+func TestAudit_Log_Without_Message_Returns_ValidationError(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	client, _ := audit.New(srv.Config())
+	_, err := client.Log(context.Background(), &audit.LogInput{Event: &audit.Event{}})
+
+	var validationErr *pangea.ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "/event/message", pangea.StringValue(validationErr.Errors[0].Source))
+	}
+	assert.Empty(t, srv.Events())
+}
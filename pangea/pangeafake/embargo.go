@@ -0,0 +1,127 @@
+package pangeafake
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+)
+
+type ipRange struct {
+	network *net.IPNet
+	isoCode string
+}
+
+// DefaultSanctions returns the sanctions of a new Server: the ITAR embargoes of Cuba, Iran,
+// North Korea and Syria.
+func DefaultSanctions() []*embargo.Sanction {
+	countries := []struct{ iso, name string }{
+		{"CU", "Cuba"},
+		{"IR", "Iran"},
+		{"KP", "North Korea"},
+		{"SY", "Syria"},
+	}
+	sanctions := make([]*embargo.Sanction, 0, len(countries))
+	for _, c := range countries {
+		sanctions = append(sanctions, &embargo.Sanction{
+			ListName:                pangea.String("ITAR"),
+			EmbargoedCountryName:    pangea.String(c.name),
+			EmbargoedCountryISOCode: pangea.String(c.iso),
+			IssuingCountry:          pangea.String("US"),
+			Annotations: map[string]interface{}{
+				"reference": map[string]interface{}{
+					"paragraph":  "d1",
+					"regulation": "CFR 126.1",
+				},
+				"restriction_name": "ITAR",
+			},
+		})
+	}
+	return sanctions
+}
+
+// SetSanctions replaces the sanctions checked by embargo.
+func (s *Server) SetSanctions(sanctions ...*embargo.Sanction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sanctions = append([]*embargo.Sanction(nil), sanctions...)
+}
+
+// SetIPCountry geolocates the IP address or CIDR range ip to the country with the ISO code,
+// for the IP checks of embargo. The IP addresses not set are not located in any country.
+func (s *Server) SetIPCountry(ip, isoCode string) error {
+	if !strings.Contains(ip, "/") {
+		if strings.Contains(ip, ":") {
+			ip += "/128"
+		} else {
+			ip += "/32"
+		}
+	}
+	_, network, err := net.ParseCIDR(ip)
+	if err != nil {
+		return fmt.Errorf("pangeafake: invalid IP address: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ipRanges = append(s.ipRanges, &ipRange{network: network, isoCode: isoCode})
+	return nil
+}
+
+func (s *Server) handleIPCheck(w http.ResponseWriter, r *http.Request) {
+	var input embargo.IPCheckInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	ip := net.ParseIP(pangea.StringValue(input.IP))
+	if ip == nil {
+		s.writeValidationError(w, fieldError{
+			Code:   "InvalidIPAddress",
+			Detail: fmt.Sprintf("'%v' is not a valid IP address", pangea.StringValue(input.IP)),
+			Source: "/ip",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	isoCode := ""
+	for _, r := range s.ipRanges {
+		if r.network.Contains(ip) {
+			isoCode = r.isoCode
+		}
+	}
+	s.mu.Unlock()
+
+	s.writeSanctions(w, isoCode)
+}
+
+func (s *Server) handleISOCheck(w http.ResponseWriter, r *http.Request) {
+	var input embargo.ISOCheckInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	if input.ISOCode == nil {
+		s.writeValidationError(w, missingProperty("/iso_code"))
+		return
+	}
+	s.writeSanctions(w, *input.ISOCode)
+}
+
+func (s *Server) writeSanctions(w http.ResponseWriter, isoCode string) {
+	s.mu.Lock()
+	sanctions := []*embargo.Sanction{}
+	for _, sanction := range s.sanctions {
+		if isoCode != "" && strings.EqualFold(pangea.StringValue(sanction.EmbargoedCountryISOCode), isoCode) {
+			sanctions = append(sanctions, sanction)
+		}
+	}
+	s.mu.Unlock()
+
+	s.writeResult(w, fmt.Sprintf("Found %v sanction(s)", len(sanctions)), &embargo.CheckOutput{
+		Count:     pangea.Int(len(sanctions)),
+		Sanctions: sanctions,
+	})
+}
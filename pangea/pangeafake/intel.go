@@ -0,0 +1,119 @@
+package pangeafake
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// The intel services sharing the v1/lookup endpoint, with the field of the looked up indicator.
+var intelIndicators = map[string]string{
+	"ip-intel":     "ip",
+	"domain-intel": "domain",
+	"url-intel":    "url",
+	"file-intel":   "hash",
+}
+
+// Verdict is the reputation of an indicator returned by the intel lookups.
+type Verdict struct {
+	// e.g. "malicious", "suspicious" or "benign"
+	Verdict  string
+	Score    int
+	Category []string
+}
+
+// DefaultVerdict is the verdict of the indicators without a verdict set.
+var DefaultVerdict = Verdict{Verdict: "benign", Score: 0, Category: []string{}}
+
+// SetVerdict sets the verdict of an indicator for service, one of "ip-intel", "domain-intel",
+// "url-intel" or "file-intel". The indicator is the IP address, domain, URL or file hash.
+func (s *Server) SetVerdict(service, indicator string, v Verdict) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.verdicts[service] == nil {
+		s.verdicts[service] = map[string]Verdict{}
+	}
+	s.verdicts[service][strings.ToLower(indicator)] = v
+}
+
+type lookupData struct {
+	Category []string `json:"category"`
+	Score    int      `json:"score"`
+	Verdict  string   `json:"verdict"`
+}
+
+type lookupOutput struct {
+	Data       lookupData     `json:"data"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+	RawData    map[string]any `json:"raw_data,omitempty"`
+}
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	var input map[string]any
+	if !s.decode(w, r, &input) {
+		return
+	}
+	service := intelService(r, input)
+	if service == "" {
+		s.writeValidationError(w, fieldError{
+			Code:   "MissingRequiredProperty",
+			Detail: "one of 'ip', 'domain', 'url' or 'hash' is required",
+			Source: "/",
+		})
+		return
+	}
+	field := intelIndicators[service]
+	indicator, _ := input[field].(string)
+	if indicator == "" {
+		s.writeValidationError(w, missingProperty("/"+field))
+		return
+	}
+
+	s.mu.Lock()
+	v, ok := s.verdicts[service][strings.ToLower(indicator)]
+	s.mu.Unlock()
+	if !ok {
+		v = DefaultVerdict
+	}
+	category := v.Category
+	if category == nil {
+		category = []string{}
+	}
+
+	out := &lookupOutput{
+		Data: lookupData{Category: category, Score: v.Score, Verdict: v.Verdict},
+	}
+	if verbose, _ := input["verbose"].(bool); verbose {
+		out.Parameters = input
+	}
+	if raw, _ := input["raw"].(bool); raw {
+		out.RawData = map[string]any{"indicator": indicator, "verdict": v.Verdict, "provider": "pangeafake"}
+	}
+	s.writeResult(w, fmt.Sprintf("Indicator is %v", v.Verdict), out)
+}
+
+// intelService returns the intel service a lookup is sent to. The service is found from the
+// config ID header if any, then from the host, e.g. "ip-intel.aws.us.pangea.cloud", and
+// finally from the field of the indicator in the request.
+func intelService(r *http.Request, input map[string]any) string {
+	for name := range r.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-pangea-") && strings.HasSuffix(name, "-config-id") {
+			service := strings.TrimSuffix(strings.TrimPrefix(name, "x-pangea-"), "-config-id")
+			if _, ok := intelIndicators[service]; ok {
+				return service
+			}
+		}
+	}
+	if service, _, ok := strings.Cut(r.Host, "."); ok {
+		if _, ok := intelIndicators[service]; ok {
+			return service
+		}
+	}
+	for service, field := range intelIndicators {
+		if _, ok := input[field]; ok {
+			return service
+		}
+	}
+	return ""
+}
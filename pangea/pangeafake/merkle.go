@@ -0,0 +1,100 @@
+package pangeafake
+
+import (
+	"strings"
+
+	"github.com/pangeacyber/go-pangea/pangea/hash"
+)
+
+// merkleTree is the Merkle tree of the audit log. The leaves are the hashes of the events,
+// a node is the hash of its left child followed by its right child and a tree of n leaves is
+// split into a perfect left subtree of the largest power of two below n leaves and a right one.
+type merkleTree struct {
+	leaves []hash.Hash
+}
+
+func (t *merkleTree) add(leaf hash.Hash) int {
+	t.leaves = append(t.leaves, leaf)
+	return len(t.leaves) - 1
+}
+
+func (t *merkleTree) size() int {
+	return len(t.leaves)
+}
+
+// root returns the root hash of the tree made of the first size leaves.
+func (t *merkleTree) root(size int) hash.Hash {
+	if size == 0 {
+		return nil
+	}
+	return subtreeRoot(t.leaves[:size])
+}
+
+// membershipProof returns the proof of the leaf at index in the tree of size leaves,
+// in the "l:<hash>,r:<hash>" format checked by audit.VerifyMembershipProof.
+func (t *merkleTree) membershipProof(index, size int) string {
+	return formatProof(pathToNode(t.leaves[:size], index, 1))
+}
+
+// consistencyProof returns the proof that the tree of oldSize leaves is a prefix of the tree
+// of newSize leaves: the roots of the perfect subtrees making the old tree, from right to left,
+// each with its membership proof in the new tree.
+func (t *merkleTree) consistencyProof(oldSize, newSize int) []*string {
+	var proof []*string
+	start := 0
+	for remaining := oldSize; remaining > 0; {
+		size := 1
+		for size*2 <= remaining {
+			size *= 2
+		}
+		node := subtreeRoot(t.leaves[start : start+size])
+		item := "x:" + node.String() + "," + formatProof(pathToNode(t.leaves[:newSize], start, size))
+		proof = append([]*string{&item}, proof...)
+		start += size
+		remaining -= size
+	}
+	return proof
+}
+
+type proofItem struct {
+	side string
+	hash hash.Hash
+}
+
+// pathToNode returns the siblings of the path from the perfect subtree of size leaves
+// starting at start up to the root, bottom up.
+func pathToNode(leaves []hash.Hash, start, size int) []proofItem {
+	if len(leaves) == size {
+		return nil
+	}
+	k := split(len(leaves))
+	if start < k {
+		return append(pathToNode(leaves[:k], start, size), proofItem{"r", subtreeRoot(leaves[k:])})
+	}
+	return append(pathToNode(leaves[k:], start-k, size), proofItem{"l", subtreeRoot(leaves[:k])})
+}
+
+func subtreeRoot(leaves []hash.Hash) hash.Hash {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := split(len(leaves))
+	return hash.Pair(subtreeRoot(leaves[:k])).With(subtreeRoot(leaves[k:]))
+}
+
+// split returns the largest power of two strictly below n, n > 1.
+func split(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+func formatProof(items []proofItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		parts = append(parts, item.side+":"+item.hash.String())
+	}
+	return strings.Join(parts, ",")
+}
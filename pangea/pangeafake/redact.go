@@ -0,0 +1,244 @@
+package pangeafake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/redact"
+)
+
+// RedactRule redacts the text matching Pattern.
+type RedactRule struct {
+	// The name of the rule, reported as the field type, e.g. "EMAIL_ADDRESS"
+	Name string
+
+	Pattern *regexp.Regexp
+
+	// The text replacing the matches, defaults to "<Name>"
+	Replacement string
+}
+
+// DefaultRedactRules returns the rules used by a new Server: email addresses, US social
+// security numbers, credit card numbers and phone numbers.
+func DefaultRedactRules() []RedactRule {
+	return []RedactRule{
+		{Name: "EMAIL_ADDRESS", Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+		{Name: "US_SSN", Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+		{Name: "CREDIT_CARD", Pattern: regexp.MustCompile(`\b(?:\d[ -]?){13,16}\b`)},
+		{Name: "PHONE_NUMBER", Pattern: regexp.MustCompile(`\(?\b\d{3}\)?[-. ]?\d{3}[-. ]?\d{4}\b`)},
+	}
+}
+
+// SetRedactRules replaces the redact rules, which are applied in order.
+func (s *Server) SetRedactRules(rules ...RedactRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redact = append([]RedactRule(nil), rules...)
+}
+
+func (s *Server) redactRules() []RedactRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.redact
+}
+
+func (s *Server) handleRedact(w http.ResponseWriter, r *http.Request) {
+	var input redact.TextInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	if input.Text == nil {
+		s.writeValidationError(w, missingProperty("/text"))
+		return
+	}
+
+	redacted, results := redactText(s.redactRules(), *input.Text)
+	out := &redact.TextOutput{RedactedText: pangea.String(redacted)}
+	if pangea.BoolValue(input.Debug) {
+		out.Report = newDebugReport(results)
+	}
+	s.writeResult(w, redactSummary(results), out)
+}
+
+func (s *Server) handleRedactStructured(w http.ResponseWriter, r *http.Request) {
+	var input redact.StructuredInput
+	if !s.decode(w, r, &input) {
+		return
+	}
+	if input.Data == nil {
+		s.writeValidationError(w, missingProperty("/data"))
+		return
+	}
+	if format := pangea.StringValue(input.Format); format != "" && format != "json" {
+		s.writeValidationError(w, fieldError{
+			Code:   "UnsupportedFormat",
+			Detail: fmt.Sprintf("format %q is not supported", format),
+			Source: "/format",
+		})
+		return
+	}
+	var data any
+	if err := json.Unmarshal(input.Data, &data); err != nil {
+		s.writeValidationError(w, fieldError{Code: "InvalidJSON", Detail: err.Error(), Source: "/data"})
+		return
+	}
+
+	rules := s.redactRules()
+	var results []*redact.RecognizerResult
+	redactValue := func(key string, v any) any {
+		str, ok := v.(string)
+		if !ok {
+			return v
+		}
+		redacted, found := redactText(rules, str)
+		for _, result := range found {
+			result.DataKey = pangea.String(key)
+		}
+		results = append(results, found...)
+		return redacted
+	}
+	if len(input.JSONP) == 0 {
+		data = walkStrings(data, "", redactValue)
+	} else {
+		for _, path := range input.JSONP {
+			data = applyJSONPath(data, "", splitJSONPath(pangea.StringValue(path)), func(key string, v any) any {
+				return walkStrings(v, key, redactValue)
+			})
+		}
+	}
+
+	redactedData, _ := json.Marshal(data)
+	out := &redact.StructuredOutput{RedactedData: redactedData}
+	if pangea.BoolValue(input.Debug) {
+		out.Report = newDebugReport(results)
+	}
+	s.writeResult(w, redactSummary(results), out)
+}
+
+type redactMatch struct {
+	rule       RedactRule
+	start, end int
+}
+
+// redactText replaces the matches of the rules in text. When matches overlap, the first
+// one wins, or the one of the first rule if they start at the same position.
+func redactText(rules []RedactRule, text string) (string, []*redact.RecognizerResult) {
+	var matches []redactMatch
+	for _, rule := range rules {
+		for _, loc := range rule.Pattern.FindAllStringIndex(text, -1) {
+			matches = append(matches, redactMatch{rule: rule, start: loc[0], end: loc[1]})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+
+	var b strings.Builder
+	var results []*redact.RecognizerResult
+	pos := 0
+	for _, m := range matches {
+		if m.start < pos || m.start == m.end {
+			continue
+		}
+		replacement := m.rule.Replacement
+		if replacement == "" {
+			replacement = "<" + m.rule.Name + ">"
+		}
+		b.WriteString(text[pos:m.start])
+		b.WriteString(replacement)
+		results = append(results, &redact.RecognizerResult{
+			FieldType: pangea.String(m.rule.Name),
+			Score:     pangea.Int(1),
+			Text:      pangea.String(text[m.start:m.end]),
+			Start:     pangea.Int(m.start),
+			End:       pangea.Int(m.end),
+			Redacted:  pangea.Bool(true),
+		})
+		pos = m.end
+	}
+	b.WriteString(text[pos:])
+	return b.String(), results
+}
+
+func newDebugReport(results []*redact.RecognizerResult) *redact.DebugReport {
+	report := &redact.DebugReport{
+		SummaryCounts:     map[string]int{},
+		RecognizerResults: results,
+	}
+	for _, result := range results {
+		report.SummaryCounts[pangea.StringValue(result.FieldType)]++
+	}
+	return report
+}
+
+func redactSummary(results []*redact.RecognizerResult) string {
+	if len(results) == 0 {
+		return "Success. No redactions"
+	}
+	return fmt.Sprintf("Success. Redacted %v item(s)", len(results))
+}
+
+// walkStrings calls f on the string values in v, replacing them with the result.
+// The key of a value is its JSON path, e.g. "a.b.0".
+func walkStrings(v any, key string, f func(key string, v any) any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = walkStrings(child, joinKey(key, k), f)
+		}
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = walkStrings(child, joinKey(key, strconv.Itoa(i)), f)
+		}
+		return v
+	}
+	return f(key, v)
+}
+
+// splitJSONPath splits a JSON path of the forms "$.a.b", "$.a[0]", "$.*.b" or "$.a[*]".
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// applyJSONPath calls f on the values selected by the path segments.
+func applyJSONPath(v any, key string, segments []string, f func(key string, v any) any) any {
+	if len(segments) == 0 {
+		return f(key, v)
+	}
+	segment, rest := segments[0], segments[1:]
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if segment == "*" || segment == k {
+				v[k] = applyJSONPath(child, joinKey(key, k), rest, f)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if segment == "*" || segment == strconv.Itoa(i) {
+				v[i] = applyJSONPath(child, joinKey(key, strconv.Itoa(i)), rest, f)
+			}
+		}
+	}
+	return v
+}
+
+func joinKey(key, child string) string {
+	if key == "" {
+		return child
+	}
+	return key + "." + child
+}
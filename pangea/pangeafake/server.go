@@ -0,0 +1,171 @@
+// Package pangeafake runs an in-memory fake of the Pangea services for end-to-end tests
+// without a Pangea account.
+//
+// The fake implements the audit log, search, results and root endpoints with a real Merkle
+// tree, so the proofs it returns can be verified, redact with configurable rules, embargo
+// with configurable sanctions and the IP, domain, URL and file intel lookups with
+// configurable verdicts.
+//
+// Example:
+//
+//	srv := pangeafake.NewServer()
+//	defer srv.Close()
+//	srv.SetVerdict("ip-intel", "93.231.182.110", pangeafake.Verdict{Verdict: "malicious", Score: 100})
+//
+//	ipintel, _ := ip_intel.New(srv.Config())
+package pangeafake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+)
+
+// DefaultToken is the token accepted by a Server unless its Token is changed.
+const DefaultToken = "pts_fake"
+
+// Server is a fake of the Pangea services. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// The bearer token accepted by the server. Requests with another token are
+	// rejected as unauthorized. If empty, any token is accepted.
+	Token string
+
+	requests atomic.Int64
+
+	mu        sync.Mutex
+	audit     auditLog
+	redact    []RedactRule
+	sanctions []*embargo.Sanction
+	ipRanges  []*ipRange
+	verdicts  map[string]map[string]Verdict
+}
+
+// NewServer starts a fake server with the default redact rules and sanctions.
+// It must be closed when done.
+func NewServer() *Server {
+	s := &Server{
+		Token:     DefaultToken,
+		audit:     newAuditLog(),
+		redact:    DefaultRedactRules(),
+		sanctions: DefaultSanctions(),
+		verdicts:  map[string]map[string]Verdict{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/log", s.handleLog)
+	mux.HandleFunc("/v1/search", s.handleSearch)
+	mux.HandleFunc("/v1/results", s.handleResults)
+	mux.HandleFunc("/v1/root", s.handleRoot)
+	mux.HandleFunc("/v1/redact", s.handleRedact)
+	mux.HandleFunc("/v1/redact_structured", s.handleRedactStructured)
+	mux.HandleFunc("/v1/ip/check", s.handleIPCheck)
+	mux.HandleFunc("/v1/iso/check", s.handleISOCheck)
+	mux.HandleFunc("/v1/lookup", s.handleLookup)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.write(w, http.StatusNotFound, "NotFound", fmt.Sprintf("endpoint %v not found", r.URL.Path), nil)
+	})
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// Config returns a config sending the requests of every service to the fake server.
+func (s *Server) Config() *pangea.Config {
+	token := s.Token
+	if token == "" {
+		token = DefaultToken
+	}
+	return &pangea.Config{
+		Token:      token,
+		Domain:     strings.TrimPrefix(s.URL, "http://"),
+		Insecure:   true,
+		Enviroment: "local",
+	}
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			s.write(w, http.StatusMethodNotAllowed, "MethodNotAllowed", fmt.Sprintf("method %v not allowed", r.Method), nil)
+			return
+		}
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || (s.Token != "" && token != s.Token) {
+			s.write(w, http.StatusUnauthorized, "Unauthorized", "invalid token", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type envelope struct {
+	RequestID    string `json:"request_id"`
+	RequestTime  string `json:"request_time"`
+	ResponseTime string `json:"response_time"`
+	StatusCode   int    `json:"status_code"`
+	Status       string `json:"status"`
+	Summary      string `json:"summary"`
+	Result       any    `json:"result"`
+}
+
+func (s *Server) writeResult(w http.ResponseWriter, summary string, result any) {
+	s.write(w, http.StatusOK, "Success", summary, result)
+}
+
+func (s *Server) write(w http.ResponseWriter, code int, status, summary string, result any) {
+	id := fmt.Sprintf("prq_fake%08d", s.requests.Add(1))
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(&envelope{
+		RequestID:    id,
+		RequestTime:  now,
+		ResponseTime: now,
+		StatusCode:   code,
+		Status:       status,
+		Summary:      summary,
+		Result:       result,
+	})
+}
+
+// fieldError is an error of the result of a ValidationError response.
+type fieldError struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Source string `json:"source"`
+}
+
+func (s *Server) writeValidationError(w http.ResponseWriter, errs ...fieldError) {
+	s.write(w, http.StatusBadRequest, "ValidationError", "There was an error validating the payload",
+		map[string]any{"errors": errs})
+}
+
+func missingProperty(source string) fieldError {
+	return fieldError{
+		Code:   "MissingRequiredProperty",
+		Detail: fmt.Sprintf("'%v' is a required property", source[strings.LastIndex(source, "/")+1:]),
+		Source: source,
+	}
+}
+
+// decode decodes the JSON body of r into v, writing a validation error if it cannot.
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		s.writeValidationError(w, fieldError{
+			Code:   "InvalidJSON",
+			Detail: err.Error(),
+			Source: "/",
+		})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package pangeafake_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/domain_intel"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+	"github.com/pangeacyber/go-pangea/service/file_intel"
+	"github.com/pangeacyber/go-pangea/service/ip_intel"
+	"github.com/pangeacyber/go-pangea/service/redact"
+	"github.com/pangeacyber/go-pangea/service/url_intel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Rejects_Invalid_Token(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	cfg := srv.Config()
+	cfg.Token = "wrong"
+	client, _ := embargo.New(cfg)
+	_, err := client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("CU")})
+	assert.ErrorIs(t, err, pangea.ErrUnauthorized)
+}
+
+func TestRedact(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	client, _ := redact.New(srv.Config())
+	resp, err := client.Redact(context.Background(), &redact.TextInput{
+		Text:  pangea.String("Mail me at jane@example.com or call 555-555-5555"),
+		Debug: pangea.Bool(true),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Mail me at <EMAIL_ADDRESS> or call <PHONE_NUMBER>", pangea.StringValue(resp.Result.RedactedText))
+	assert.Equal(t, map[string]int{"EMAIL_ADDRESS": 1, "PHONE_NUMBER": 1}, resp.Result.Report.SummaryCounts)
+	assert.Equal(t, "jane@example.com", pangea.StringValue(resp.Result.Report.RecognizerResults[0].Text))
+
+	srv.SetRedactRules(pangeafake.RedactRule{Name: "SECRET", Pattern: regexp.MustCompile(`s3cr3t`), Replacement: "***"})
+	resp, err = client.Redact(context.Background(), &redact.TextInput{Text: pangea.String("my s3cr3t, jane@example.com")})
+	assert.NoError(t, err)
+	assert.Equal(t, "my ***, jane@example.com", pangea.StringValue(resp.Result.RedactedText))
+}
+
+func TestRedactStructured(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	client, _ := redact.New(srv.Config())
+	input := &redact.StructuredInput{
+		JSONP: []*string{pangea.String("$.*.secret")},
+	}
+	input.SetData(map[string]any{
+		"one": map[string]any{"secret": "123-45-6789", "public": "123-45-6789"},
+	})
+	resp, err := client.RedactStructured(context.Background(), input)
+	assert.NoError(t, err)
+
+	var data map[string]map[string]string
+	assert.NoError(t, resp.Result.GetRedactedData(&data))
+	assert.Equal(t, "<US_SSN>", data["one"]["secret"])
+	assert.Equal(t, "123-45-6789", data["one"]["public"])
+}
+
+func TestEmbargo(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	assert.NoError(t, srv.SetIPCountry("200.0.16.0/24", "CU"))
+
+	client, _ := embargo.New(srv.Config())
+	resp, err := client.IPCheck(context.Background(), &embargo.IPCheckInput{IP: pangea.String("200.0.16.2")})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pangea.IntValue(resp.Result.Count))
+	assert.Equal(t, "Cuba", pangea.StringValue(resp.Result.Sanctions[0].EmbargoedCountryName))
+
+	resp, err = client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("FR")})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pangea.IntValue(resp.Result.Count))
+
+	srv.SetSanctions(&embargo.Sanction{ListName: pangea.String("EU"), EmbargoedCountryISOCode: pangea.String("FR")})
+	resp, err = client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("fr")})
+	assert.NoError(t, err)
+	assert.Equal(t, "EU", pangea.StringValue(resp.Result.Sanctions[0].ListName))
+}
+
+func TestIntelLookups(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+
+	malicious := pangeafake.Verdict{Verdict: "malicious", Score: 100, Category: []string{"malware"}}
+	srv.SetVerdict("ip-intel", "93.231.182.110", malicious)
+	srv.SetVerdict("domain-intel", "evil.example", malicious)
+	srv.SetVerdict("url-intel", "http://evil.example/payload", malicious)
+	srv.SetVerdict("file-intel", "322ccbd42b7e4fd3a9d0167ca2fa9f6483d9691364c431625f1df54270647ca8", malicious)
+	ctx := context.Background()
+
+	ipcli, _ := ip_intel.New(srv.Config())
+	ip, err := ipcli.Lookup(ctx, &ip_intel.IpLookupInput{Ip: "93.231.182.110", Raw: true})
+	assert.NoError(t, err)
+	assert.Equal(t, ip_intel.LookupData{Category: []string{"malware"}, Score: 100, Verdict: "malicious"}, ip.Result.Data)
+	assert.NotNil(t, ip.Result.RawData)
+
+	domaincli, _ := domain_intel.New(srv.Config())
+	domain, err := domaincli.Lookup(ctx, &domain_intel.DomainLookupInput{Domain: "evil.example"})
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", domain.Result.Data.Verdict)
+
+	urlcli, _ := url_intel.New(srv.Config())
+	url, err := urlcli.Lookup(ctx, &url_intel.UrlLookupInput{Url: "http://evil.example/payload"})
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", url.Result.Data.Verdict)
+
+	// The IP looked up by domain intel is not malicious for this service.
+	domain, err = domaincli.Lookup(ctx, &domain_intel.DomainLookupInput{Domain: "93.231.182.110"})
+	assert.NoError(t, err)
+	assert.Equal(t, "benign", domain.Result.Data.Verdict)
+
+	cfg := srv.Config()
+	cfg.CfgToken = "pci_fake"
+	filecli, _ := file_intel.New(cfg)
+	file, err := filecli.Lookup(ctx, &file_intel.FileLookupInput{
+		Hash:     "322ccbd42b7e4fd3a9d0167ca2fa9f6483d9691364c431625f1df54270647ca8",
+		HashType: "sha256",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", file.Result.Data.Verdict)
+}
@@ -0,0 +1,163 @@
+// Package pangeaotel instruments Pangea service calls with OpenTelemetry.
+//
+// Add the middleware to the config shared by the service clients:
+//
+//	cfg.Middlewares = append(cfg.Middlewares, pangeaotel.Middleware())
+//
+// Every request sent by a client built from cfg then emits a client span,
+// records latency and error metrics and propagates the trace context to Pangea.
+package pangeaotel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+const instrumentationName = "github.com/pangeacyber/go-pangea/pangea/pangeaotel"
+
+// Attribute keys set on spans and metrics.
+const (
+	ServiceKey   = attribute.Key("pangea.service")
+	EndpointKey  = attribute.Key("pangea.endpoint")
+	RequestIDKey = attribute.Key("pangea.request_id")
+	StatusKey    = attribute.Key("pangea.status")
+	SummaryKey   = attribute.Key("pangea.summary")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+type Option func(*config)
+
+// WithTracerProvider sets the provider used to create spans. It defaults to the global provider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the provider used to record metrics. It defaults to the global provider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithPropagators sets the propagators used to inject the trace context into
+// outbound requests. It defaults to the global propagators.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = p
+	}
+}
+
+type instruments struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+	errors   metric.Int64Counter
+}
+
+// Middleware returns a pangea.Middleware that emits a span per request and records the
+// pangea.client.duration histogram and the pangea.client.requests and pangea.client.errors counters.
+func Middleware(opts ...Option) pangea.Middleware {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.tracerProvider == nil {
+		cfg.tracerProvider = otel.GetTracerProvider()
+	}
+	if cfg.meterProvider == nil {
+		cfg.meterProvider = otel.GetMeterProvider()
+	}
+	if cfg.propagators == nil {
+		cfg.propagators = otel.GetTextMapPropagator()
+	}
+
+	inst := newInstruments(cfg)
+	return func(next pangea.Handler) pangea.Handler {
+		return func(ctx context.Context, req *pangea.Request) (*pangea.Response, error) {
+			return inst.handle(ctx, cfg.propagators, next, req)
+		}
+	}
+}
+
+func newInstruments(cfg *config) *instruments {
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	inst := &instruments{
+		tracer: cfg.tracerProvider.Tracer(instrumentationName),
+	}
+
+	// The meter only fails on invalid instrument names, which are constant here,
+	// and still returns a usable no-op instrument along with the error.
+	inst.duration, _ = meter.Float64Histogram("pangea.client.duration",
+		metric.WithDescription("Duration of the requests sent to Pangea services."),
+		metric.WithUnit("s"))
+	inst.requests, _ = meter.Int64Counter("pangea.client.requests",
+		metric.WithDescription("Number of requests sent to Pangea services."),
+		metric.WithUnit("{request}"))
+	inst.errors, _ = meter.Int64Counter("pangea.client.errors",
+		metric.WithDescription("Number of requests to Pangea services that failed."),
+		metric.WithUnit("{request}"))
+	return inst
+}
+
+func (inst *instruments) handle(ctx context.Context, propagators propagation.TextMapPropagator, next pangea.Handler, req *pangea.Request) (*pangea.Response, error) {
+	attrs := []attribute.KeyValue{
+		ServiceKey.String(req.ServiceName),
+		EndpointKey.String(req.Path),
+	}
+
+	ctx, span := inst.tracer.Start(ctx, fmt.Sprintf("%v %v", req.ServiceName, req.Path),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(attribute.String("http.request.method", req.HTTPRequest.Method)),
+	)
+	defer span.End()
+
+	propagators.Inject(ctx, propagation.HeaderCarrier(req.HTTPRequest.Header))
+
+	start := time.Now()
+	resp, err := next(ctx, req)
+	elapsed := time.Since(start)
+
+	if resp != nil {
+		status := pangea.StringValue(resp.Status)
+		attrs = append(attrs, StatusKey.String(status))
+		span.SetAttributes(
+			RequestIDKey.String(pangea.StringValue(resp.RequestID)),
+			StatusKey.String(status),
+			SummaryKey.String(pangea.StringValue(resp.Summary)),
+		)
+		if resp.HTTPResponse != nil {
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.HTTPResponse.StatusCode))
+		}
+	}
+
+	set := metric.WithAttributes(attrs...)
+	inst.duration.Record(ctx, elapsed.Seconds(), set)
+	inst.requests.Add(ctx, 1, set)
+	// Accepted requests are not failures, their result is fetched by later requests.
+	var acceptedErr *pangea.AcceptedError
+	if err != nil && !errors.As(err, &acceptedErr) {
+		inst.errors.Add(ctx, 1, set)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return resp, err
+}
@@ -0,0 +1,113 @@
+package pangeaotel_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeaotel"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+)
+
+func setup(t *testing.T, path string, status int, body string) (*embargo.Embargo, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	mux, url, teardown := pangeatesting.SetupServer()
+	t.Cleanup(teardown)
+
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("traceparent"))
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Middlewares = []pangea.Middleware{
+		pangeaotel.Middleware(
+			pangeaotel.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+			pangeaotel.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+			pangeaotel.WithPropagators(propagation.TraceContext{}),
+		),
+	}
+	client, _ := embargo.New(cfg)
+	return client, spans, reader
+}
+
+func sumOf(t *testing.T, reader *sdkmetric.ManualReader, name string) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == name {
+				for _, dp := range sum.DataPoints {
+					total += dp.Value
+				}
+			}
+		}
+	}
+	return total
+}
+
+func TestMiddleware_Records_Span_And_Metrics(t *testing.T) {
+	client, spans, reader := setup(t, "/v1/ip/check", http.StatusOK, `{
+		"request_id": "some-id",
+		"status_code": 200,
+		"status": "Success",
+		"result": {"count": 0, "sanctions": []},
+		"summary": "Found 0 sanctions"
+	}`)
+
+	_, err := client.IPCheck(context.Background(), &embargo.IPCheckInput{IP: pangea.String("1.1.1.1")})
+	assert.NoError(t, err)
+
+	ended := spans.Ended()
+	if assert.Len(t, ended, 1) {
+		span := ended[0]
+		assert.Equal(t, "embargo v1/ip/check", span.Name())
+		assert.Contains(t, span.Attributes(), pangeaotel.RequestIDKey.String("some-id"))
+		assert.Contains(t, span.Attributes(), pangeaotel.StatusKey.String("Success"))
+		assert.Contains(t, span.Attributes(), pangeaotel.SummaryKey.String("Found 0 sanctions"))
+		assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusOK))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	}
+	assert.Equal(t, int64(1), sumOf(t, reader, "pangea.client.requests"))
+	assert.Equal(t, int64(0), sumOf(t, reader, "pangea.client.errors"))
+}
+
+func TestMiddleware_Records_Errors(t *testing.T) {
+	client, spans, reader := setup(t, "/v1/iso/check", http.StatusBadRequest, `{
+		"request_id": "some-id",
+		"status_code": 400,
+		"status": "ValidationError",
+		"result": null,
+		"summary": "bad request"
+	}`)
+
+	_, err := client.ISOCheck(context.Background(), &embargo.ISOCheckInput{})
+	assert.Error(t, err)
+
+	ended := spans.Ended()
+	if assert.Len(t, ended, 1) {
+		span := ended[0]
+		assert.Equal(t, "embargo v1/iso/check", span.Name())
+		assert.Contains(t, span.Attributes(), pangeaotel.StatusKey.String("ValidationError"))
+		assert.Equal(t, codes.Error, span.Status().Code)
+	}
+	assert.Equal(t, int64(1), sumOf(t, reader, "pangea.client.requests"))
+	assert.Equal(t, int64(1), sumOf(t, reader, "pangea.client.errors"))
+}
@@ -0,0 +1,365 @@
+package pangeatest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+// Chaos is an http.RoundTripper injecting faults in the requests sent to Pangea, to test
+// how the code calling Pangea handles slow or failing services:
+//
+//	chaos := pangeatest.NewChaos(nil)
+//	chaos.Inject(pangeatest.ServerError(503)).On("audit", "v1/log").Times(2)
+//	chaos.Inject(pangeatest.Latency(2 * time.Second)).Probability(0.1)
+//
+//	cfg.Transport = chaos
+//	auditcli, _ := audit.New(cfg)
+//
+// With Config.Transport the requests go through the retry client if Config.Retry is set,
+// with Config.HTTPClient set to Client() they are sent once.
+//
+// The rules are matched in the order they were added, the first matching rule injects its
+// fault and the requests matching no rule are sent with the base transport.
+type Chaos struct {
+	base http.RoundTripper
+
+	mu    sync.Mutex
+	rules []*Rule
+	rand  *rand.Rand
+}
+
+// NewChaos returns a Chaos sending the requests with base, http.DefaultTransport if nil.
+// Its random numbers are seeded with a constant, for the tests to be reproducible.
+func NewChaos(base http.RoundTripper) *Chaos {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Chaos{
+		base: base,
+		rand: rand.New(rand.NewSource(1)),
+	}
+}
+
+// Client returns an HTTP client sending its requests through c.
+func (c *Chaos) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Inject adds a rule injecting fault in every request, until it is restricted.
+func (c *Chaos) Inject(fault Fault) *Rule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := &Rule{chaos: c, fault: fault, probability: 1}
+	c.rules = append(c.rules, r)
+	return r
+}
+
+// Reset removes the rules.
+func (c *Chaos) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = nil
+}
+
+func (c *Chaos) RoundTrip(req *http.Request) (*http.Response, error) {
+	service, path := pangea.RequestEndpoint(req)
+	path = strings.TrimPrefix(path, "/")
+
+	// The polls of the requests accepted by a fault are answered by the fault.
+	if id, ok := strings.CutPrefix(path, "request/"); ok {
+		for _, f := range c.acceptedFaults() {
+			if resp, ok, err := f.poll(id, req, c.base); ok {
+				return resp, err
+			}
+		}
+	}
+
+	if r := c.match(service, path); r != nil {
+		return r.fault.Inject(req, c.base)
+	}
+	return c.base.RoundTrip(req)
+}
+
+// match returns the first rule matching the request and counts the hit.
+func (c *Chaos) match(service, path string) *Rule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range c.rules {
+		if r.service != "" && r.service != service || r.path != "" && r.path != path {
+			continue
+		}
+		if r.times > 0 && r.hits >= r.times {
+			continue
+		}
+		if r.probability < 1 && c.rand.Float64() >= r.probability {
+			continue
+		}
+		r.hits++
+		return r
+	}
+	return nil
+}
+
+func (c *Chaos) acceptedFaults() []*acceptedFault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var faults []*acceptedFault
+	for _, r := range c.rules {
+		if f, ok := r.fault.(*acceptedFault); ok {
+			faults = append(faults, f)
+		}
+	}
+	return faults
+}
+
+// Rule selects the requests a fault is injected in.
+type Rule struct {
+	chaos       *Chaos
+	fault       Fault
+	service     string
+	path        string
+	times       int
+	probability float64
+	hits        int
+}
+
+// On restricts the rule to the requests to the endpoint path of service, e.g. "audit"
+// and "v1/log". An empty service or path matches any.
+func (r *Rule) On(service, path string) *Rule {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+	r.service = service
+	r.path = strings.TrimPrefix(path, "/")
+	return r
+}
+
+// Times limits the rule to the n first matching requests, the later ones are matched
+// by the next rules.
+func (r *Rule) Times(n int) *Rule {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+	r.times = n
+	return r
+}
+
+// Probability injects the fault in the matching requests with probability p, between 0 and 1.
+func (r *Rule) Probability(p float64) *Rule {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+	r.probability = p
+	return r
+}
+
+// Hits returns the number of requests the fault was injected in.
+func (r *Rule) Hits() int {
+	r.chaos.mu.Lock()
+	defer r.chaos.mu.Unlock()
+	return r.hits
+}
+
+// A Fault is injected by Chaos in the requests matching its rule.
+type Fault interface {
+	// Inject returns the response to req, or an error. It can send req with next,
+	// e.g. after a delay.
+	Inject(req *http.Request, next http.RoundTripper) (*http.Response, error)
+}
+
+// FaultFunc is a function implementing Fault.
+type FaultFunc func(req *http.Request, next http.RoundTripper) (*http.Response, error)
+
+func (f FaultFunc) Inject(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	return f(req, next)
+}
+
+// Latency delays the requests by d before sending them, or until their context is done.
+func Latency(d time.Duration) Fault {
+	return FaultFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		if err := sleep(req.Context(), d); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+// ConnectionReset fails the requests with a connection reset by peer error, without
+// sending them.
+func ConnectionReset() Fault {
+	return FaultFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		closeBody(req)
+		return nil, &net.OpError{
+			Op:  "read",
+			Net: "tcp",
+			Err: os.NewSyscallError("read", syscall.ECONNRESET),
+		}
+	})
+}
+
+// ServerError answers the requests with a Pangea error of statusCode, e.g. 503,
+// without sending them.
+func ServerError(statusCode int) Fault {
+	status := "InternalError"
+	if statusCode == http.StatusServiceUnavailable {
+		status = "ServiceNotAvailable"
+	}
+	return FaultFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		closeBody(req)
+		return envelopeResponse(req, statusCode, newRequestID(), status, http.StatusText(statusCode)), nil
+	})
+}
+
+// RateLimited answers the requests with a 429 Pangea error asking to retry after retryAfter,
+// rounded up to the second, without sending them.
+func RateLimited(retryAfter time.Duration) Fault {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	return FaultFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		closeBody(req)
+		resp := envelopeResponse(req, http.StatusTooManyRequests, newRequestID(), "TooManyRequests", "Too many requests")
+		resp.Header.Set("Retry-After", strconv.Itoa(seconds))
+		return resp, nil
+	})
+}
+
+// MalformedJSON answers the requests with a truncated JSON envelope, without sending them.
+func MalformedJSON() Fault {
+	return FaultFunc(func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		closeBody(req)
+		body := fmt.Sprintf(`{"request_id": "%v", "status_code": 200, "status": "Success", "result": {"`, newRequestID())
+		return newResponse(req, http.StatusOK, []byte(body)), nil
+	})
+}
+
+// Accepted answers the requests with 202 Accepted, as Pangea does for the requests taking
+// long to process. The result, polled from the request/{id} endpoint, is not ready for
+// the polls first polls, then the request is sent and its response returned.
+func Accepted(polls int) Fault {
+	return &acceptedFault{polls: polls, pending: map[string]*acceptedRequest{}}
+}
+
+type acceptedFault struct {
+	polls int
+
+	mu      sync.Mutex
+	pending map[string]*acceptedRequest
+}
+
+type acceptedRequest struct {
+	req   *http.Request
+	body  []byte
+	polls int
+}
+
+func (f *acceptedFault) Inject(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	id := newRequestID()
+	f.mu.Lock()
+	f.pending[id] = &acceptedRequest{
+		req:   req.Clone(context.Background()),
+		body:  body,
+		polls: f.polls,
+	}
+	f.mu.Unlock()
+	return envelopeResponse(req, http.StatusAccepted, id, "Accepted", "Your request is in progress"), nil
+}
+
+// poll answers the poll of the accepted request id, ok is false if the request is unknown.
+func (f *acceptedFault) poll(id string, req *http.Request, next http.RoundTripper) (*http.Response, bool, error) {
+	f.mu.Lock()
+	accepted, ok := f.pending[id]
+	if !ok {
+		f.mu.Unlock()
+		return nil, false, nil
+	}
+	if accepted.polls > 0 {
+		accepted.polls--
+		f.mu.Unlock()
+		closeBody(req)
+		return envelopeResponse(req, http.StatusAccepted, id, "Accepted", "Your request is in progress"), true, nil
+	}
+	delete(f.pending, id)
+	f.mu.Unlock()
+
+	closeBody(req)
+	original := accepted.req.Clone(req.Context())
+	original.Body = io.NopCloser(bytes.NewReader(accepted.body))
+	resp, err := next.RoundTrip(original)
+	return resp, true, err
+}
+
+var requestIDs atomic.Int64
+
+func newRequestID() string {
+	return fmt.Sprintf("prq_chaos%08d", requestIDs.Add(1))
+}
+
+// envelopeResponse returns a response with a Pangea envelope without result.
+func envelopeResponse(req *http.Request, statusCode int, requestID, status, summary string) *http.Response {
+	now := time.Now().UTC()
+	body, _ := json.Marshal(map[string]any{
+		"request_id":    requestID,
+		"request_time":  now,
+		"response_time": now,
+		"status_code":   statusCode,
+		"status":        status,
+		"summary":       summary,
+		"result":        nil,
+	})
+	return newResponse(req, statusCode, body)
+}
+
+func newResponse(req *http.Request, statusCode int, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode:    statusCode,
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// closeBody closes the body of a request not sent, as required from a RoundTripper.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,135 @@
+package pangeatest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeatest"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupEmbargo(t *testing.T) (cfg *pangea.Config, calls *int) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	t.Cleanup(teardown)
+
+	calls = new(int)
+	mux.HandleFunc("/v1/iso/check", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		fmt.Fprint(w,
+			`{
+				"request_id": "some-id",
+				"status_code": 200,
+				"status": "Success",
+				"result": {"sanctions": [], "count": 0},
+				"summary": "Found 0 sanction(s)"
+			}`)
+	})
+	return pangeatesting.TestConfig(url), calls
+}
+
+func isoCheck(client *embargo.Embargo) (*pangea.PangeaResponse[embargo.CheckOutput], error) {
+	return client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("CU")})
+}
+
+func TestChaos_Server_Errors_Are_Retried(t *testing.T) {
+	cfg, calls := setupEmbargo(t)
+	chaos := pangeatest.NewChaos(nil)
+	rule := chaos.Inject(pangeatest.ServerError(503)).On("embargo", "v1/iso/check").Times(2)
+	chaos.Inject(pangeatest.ConnectionReset()).On("embargo", "v1/ip/check")
+
+	cfg.Transport = chaos
+	cfg.Retry = true
+	cfg.RetryConfig = &pangea.RetryConfig{RetryWaitMin: time.Millisecond, RetryWaitMax: time.Millisecond, RetryMax: 3}
+	client, _ := embargo.New(cfg)
+
+	resp, err := isoCheck(client)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, resp.Attempts)
+	assert.Equal(t, 2, rule.Hits())
+	assert.Equal(t, 1, *calls)
+}
+
+func TestChaos_Injects_Errors(t *testing.T) {
+	cfg, calls := setupEmbargo(t)
+	chaos := pangeatest.NewChaos(nil)
+	cfg.HTTPClient = chaos.Client()
+	client, _ := embargo.New(cfg)
+
+	chaos.Inject(pangeatest.RateLimited(1500 * time.Millisecond)).Times(1)
+	_, err := isoCheck(client)
+	var rateLimitedErr *pangea.RateLimitedError
+	assert.ErrorAs(t, err, &rateLimitedErr)
+	assert.Equal(t, 2*time.Second, rateLimitedErr.RetryAfter)
+
+	chaos.Inject(pangeatest.ConnectionReset()).Times(1)
+	_, err = isoCheck(client)
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+
+	chaos.Inject(pangeatest.MalformedJSON()).Times(1)
+	_, err = isoCheck(client)
+	var unmarshalErr *pangea.UnMarshalError
+	assert.ErrorAs(t, err, &unmarshalErr)
+
+	chaos.Inject(pangeatest.ServerError(500)).On("embargo", "v1/ip/check")
+	_, err = isoCheck(client)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestChaos_Latency_Honors_Context(t *testing.T) {
+	cfg, calls := setupEmbargo(t)
+	chaos := pangeatest.NewChaos(nil)
+	chaos.Inject(pangeatest.Latency(time.Minute))
+	cfg.HTTPClient = chaos.Client()
+	client, _ := embargo.New(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.ISOCheck(ctx, &embargo.ISOCheckInput{ISOCode: pangea.String("CU")})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 0, *calls)
+}
+
+func TestChaos_Accepted_Requests_Are_Polled(t *testing.T) {
+	cfg, calls := setupEmbargo(t)
+	chaos := pangeatest.NewChaos(nil)
+	rule := chaos.Inject(pangeatest.Accepted(2)).On("embargo", "v1/iso/check")
+	cfg.HTTPClient = chaos.Client()
+
+	client, _ := embargo.New(cfg)
+	_, err := isoCheck(client)
+	var acceptedErr *pangea.AcceptedError
+	assert.ErrorAs(t, err, &acceptedErr)
+	assert.Equal(t, 0, *calls)
+
+	cfg.PollAccepted = true
+	cfg.PollConfig = &pangea.PollConfig{PollInterval: time.Millisecond, Backoff: 1}
+	client, _ = embargo.New(cfg)
+	resp, err := isoCheck(client)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pangea.IntValue(resp.Result.Count))
+	assert.Equal(t, 2, rule.Hits())
+	assert.Equal(t, 1, *calls)
+}
+
+func TestChaos_Probability(t *testing.T) {
+	cfg, calls := setupEmbargo(t)
+	chaos := pangeatest.NewChaos(nil)
+	rule := chaos.Inject(pangeatest.ServerError(500)).Probability(0.5)
+	cfg.HTTPClient = chaos.Client()
+	client, _ := embargo.New(cfg)
+
+	for i := 0; i < 100; i++ {
+		isoCheck(client)
+	}
+	assert.InDelta(t, 50, rule.Hits(), 15)
+	assert.Equal(t, 100, rule.Hits()+*calls)
+}
@@ -0,0 +1,355 @@
+// Package pangeatest helps testing code that calls Pangea services without a Pangea account.
+//
+// A Recorder is an http.RoundTripper that records the requests sent to Pangea and their
+// responses to a cassette file, then replays them in later runs:
+//
+//	func TestMyCode(t *testing.T) {
+//		rec := pangeatest.Start(t, "testdata/my_code.json")
+//		cfg := rec.Config()
+//		auditcli, _ := audit.New(cfg)
+//		...
+//	}
+//
+// The cassette is recorded against the real services when the PANGEA_RECORD environment
+// variable is set, with the configuration read by pangea.ConfigFromEnv, and replayed otherwise.
+package pangeatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+// EnvRecord is the environment variable switching Start to record mode.
+const EnvRecord = "PANGEA_RECORD"
+
+// Mode is the mode of a Recorder.
+type Mode int
+
+const (
+	// ModeReplay serves the responses of the cassette, without sending requests.
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests and records them with their responses.
+	ModeRecord
+)
+
+// Cassette holds the interactions recorded with Pangea.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request sent to Pangea and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request as stored in a cassette. Its headers are not recorded,
+// so the tokens are not stored.
+type RecordedRequest struct {
+	// The service of the request, e.g. "audit"
+	Service string `json:"service"`
+
+	Method string `json:"method"`
+
+	// The path of the endpoint, e.g. "v1/log"
+	Path string `json:"path"`
+
+	// The canonical JSON body, with sorted keys and no spaces
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response as stored in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+
+	// The body if it is JSON, as all Pangea responses are
+	Body json.RawMessage `json:"body,omitempty"`
+
+	// The body if it is not JSON
+	BodyText string `json:"body_text,omitempty"`
+}
+
+// Recorder records or replays the interactions of a cassette file.
+type Recorder struct {
+	mode Mode
+	name string
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewRecorder returns a Recorder for the named cassette file.
+// In replay mode the cassette is loaded, in record mode the requests are sent with base,
+// http.DefaultTransport if nil, and the cassette is written by Save.
+func NewRecorder(name string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{
+		mode:     mode,
+		name:     name,
+		base:     base,
+		cassette: &Cassette{},
+	}
+	if mode == ModeReplay {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("pangeatest: cannot read cassette: %w", err)
+		}
+		if err := json.Unmarshal(b, r.cassette); err != nil {
+			return nil, fmt.Errorf("pangeatest: cannot decode cassette %v: %w", name, err)
+		}
+		// The bodies are indented in the file.
+		for _, interaction := range r.cassette.Interactions {
+			if interaction.Request.Body, err = canonicalJSON(interaction.Request.Body); err != nil {
+				return nil, err
+			}
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Start returns a Recorder for the named cassette file, in record mode if the PANGEA_RECORD
+// environment variable is set and in replay mode otherwise. The cassette is saved when
+// the test and its subtests complete.
+func Start(t testing.TB, name string) *Recorder {
+	t.Helper()
+	mode := ModeReplay
+	if os.Getenv(EnvRecord) != "" {
+		mode = ModeRecord
+	}
+	r, err := NewRecorder(name, mode, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := r.Save(); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Mode returns the mode of the recorder.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client sending its requests through the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Config returns a config with an HTTP client sending its requests through the recorder.
+// In record mode it is read from the environment by pangea.ConfigFromEnv, in replay mode
+// it has placeholder token and domain, as they are not needed.
+func (r *Recorder) Config() *pangea.Config {
+	cfg := &pangea.Config{
+		Token:  "replay-token",
+		Domain: "pangea.test",
+	}
+	if r.mode == ModeRecord {
+		var err error
+		if cfg, err = pangea.ConfigFromEnv(); err != nil {
+			// The missing domain is reported by the service constructor, the recorder has no test to fail.
+			cfg = &pangea.Config{}
+		}
+	}
+	cfg.HTTPClient = r.Client()
+	return cfg
+}
+
+// Save writes the recorded interactions to the cassette file, creating its directory if
+// needed. It does nothing in replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("pangeatest: cannot encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.name), 0o755); err != nil {
+		return fmt.Errorf("pangeatest: cannot write cassette: %w", err)
+	}
+	if err := os.WriteFile(r.name, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("pangeatest: cannot write cassette: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	scrub := secretsScrubber(req.Header)
+	recorded.Body = json.RawMessage(scrub.Replace(string(recorded.Body)))
+	b = []byte(scrub.Replace(string(b)))
+	interaction := &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(scrub, resp.Header),
+		},
+	}
+	if json.Valid(b) {
+		interaction.Response.Body = b
+	} else {
+		interaction.Response.BodyText = string(b)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay serves the first interaction matching the request that was not served yet,
+// or the last matching one if they were all served, e.g. when polling for a result.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !interaction.Request.matches(recorded) {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("pangeatest: no interaction recorded in %v for %v %v %v %s",
+			r.name, recorded.Service, recorded.Method, recorded.Path, recorded.Body)
+	}
+	r.used[match] = true
+
+	recordedResp := r.cassette.Interactions[match].Response
+	body := []byte(recordedResp.Body)
+	if len(body) == 0 {
+		body = []byte(recordedResp.BodyText)
+	}
+	resp := newResponse(req, recordedResp.StatusCode, body)
+	resp.Header = recordedResp.Header.Clone()
+	return resp, nil
+}
+
+// Scrubbed replaces the tokens and config IDs in the recorded bodies and headers.
+const Scrubbed = "<scrubbed>"
+
+// secretsScrubber returns a replacer of the bearer token and the config IDs sent in h.
+func secretsScrubber(h http.Header) *strings.Replacer {
+	var oldnew []string
+	for name, values := range h {
+		name = strings.ToLower(name)
+		isConfigID := strings.HasPrefix(name, "x-pangea-") && strings.HasSuffix(name, "-config-id")
+		if name != "authorization" && !isConfigID {
+			continue
+		}
+		for _, v := range values {
+			v = strings.TrimPrefix(v, "Bearer ")
+			if v != "" {
+				oldnew = append(oldnew, v, Scrubbed)
+			}
+		}
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+func scrubHeader(scrub *strings.Replacer, h http.Header) http.Header {
+	scrubbed := make(http.Header, len(h))
+	for name, values := range h {
+		for _, v := range values {
+			scrubbed.Add(name, scrub.Replace(v))
+		}
+	}
+	return scrubbed
+}
+
+func (r RecordedRequest) matches(other RecordedRequest) bool {
+	return r.Service == other.Service && r.Path == other.Path && bytes.Equal(r.Body, other.Body)
+}
+
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	service, path := pangea.RequestEndpoint(req)
+	recorded := RecordedRequest{
+		Service: service,
+		Method:  req.Method,
+		Path:    strings.TrimPrefix(path, "/"),
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return recorded, fmt.Errorf("pangeatest: cannot read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	recorded.Body, err = canonicalJSON(b)
+	if err != nil {
+		return recorded, err
+	}
+	return recorded, nil
+}
+
+// canonicalJSON returns the JSON document with sorted keys and no spaces, so that equal
+// documents have the same representation. Other bodies are recorded as JSON strings.
+func canonicalJSON(b []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, nil
+	}
+	var v any = string(b)
+	if json.Valid(b) {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, fmt.Errorf("pangeatest: cannot decode request body: %w", err)
+		}
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("pangeatest: cannot encode request body: %w", err)
+	}
+	return canonical, nil
+}
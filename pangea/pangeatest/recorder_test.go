@@ -0,0 +1,98 @@
+package pangeatest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeatest"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Replays_Recorded_Interactions(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	mux.HandleFunc("/v1/iso/check", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w,
+			`{
+				"request_id": "some-id",
+				"status_code": 200,
+				"status": "success",
+				"result": {"sanctions": [], "count": 0},
+				"summary": "checked TestToken"
+			}`)
+	})
+
+	name := filepath.Join(t.TempDir(), "testdata", "embargo.json")
+	rec, err := pangeatest.NewRecorder(name, pangeatest.ModeRecord, nil)
+	assert.NoError(t, err)
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.CfgToken = "pci_secret"
+	cfg.HTTPClient = rec.Client()
+	client, _ := embargo.New(cfg)
+	recorded, err := client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("CU")})
+	assert.NoError(t, err)
+	assert.NoError(t, rec.Save())
+
+	b, err := os.ReadFile(name)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "TestToken")
+	assert.NotContains(t, string(b), "pci_secret")
+	assert.Contains(t, string(b), `"service": "embargo"`)
+	assert.Contains(t, string(b), `"path": "v1/iso/check"`)
+
+	rec, err = pangeatest.NewRecorder(name, pangeatest.ModeReplay, nil)
+	assert.NoError(t, err)
+	client, _ = embargo.New(rec.Config())
+	replayed, err := client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("CU")})
+	assert.NoError(t, err)
+	assert.Equal(t, recorded.Result, replayed.Result)
+	assert.Equal(t, "some-id", pangea.StringValue(replayed.RequestID))
+	assert.Equal(t, "checked "+pangeatest.Scrubbed, pangea.StringValue(replayed.Summary))
+
+	_, err = client.ISOCheck(context.Background(), &embargo.ISOCheckInput{ISOCode: pangea.String("US")})
+	assert.ErrorContains(t, err, "no interaction recorded")
+}
+
+func TestRecorder_Matches_Canonical_Body(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cassette.json")
+	err := os.WriteFile(name, []byte(`{
+		"interactions": [
+			{
+				"request": {"service": "svc", "method": "POST", "path": "v1/test", "body": {"b": 1, "a": "x"}},
+				"response": {"status_code": 202, "body": {"request_id": "first", "status_code": 202, "status": "Accepted", "result": null}}
+			},
+			{
+				"request": {"service": "svc", "method": "POST", "path": "v1/test", "body": {"a": "x", "b": 1}},
+				"response": {"status_code": 200, "body": {"request_id": "second", "status_code": 200, "status": "Success", "result": null}}
+			}
+		]
+	}`), 0o644)
+	assert.NoError(t, err)
+
+	rec, err := pangeatest.NewRecorder(name, pangeatest.ModeReplay, nil)
+	assert.NoError(t, err)
+	client := pangea.NewClient("svc", rec.Config())
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		req, _ := client.NewRequest("POST", "v1/test", map[string]any{"b": 1, "a": "x"})
+		resp, err := client.Do(context.Background(), req, nil)
+		if resp == nil {
+			var acceptedErr *pangea.AcceptedError
+			assert.ErrorAs(t, err, &acceptedErr)
+			ids = append(ids, acceptedErr.ReqID())
+			continue
+		}
+		ids = append(ids, pangea.StringValue(resp.RequestID))
+	}
+	assert.Equal(t, []string{"first", "second", "second"}, ids)
+}
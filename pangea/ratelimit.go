@@ -0,0 +1,75 @@
+package pangea
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+type RateLimitConfig struct {
+	RequestsPerSecond float64 // Maximum sustained rate of requests, 0 means unlimited
+	Burst             int     // Maximum number of requests sent at once above the rate, defaults to 1
+	MaxInFlight       int     // Maximum number of concurrent requests, 0 means unlimited
+}
+
+// rateLimiter enforces a RateLimitConfig on the requests sent by a Client.
+type rateLimiter struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+func newRateLimiter(cfg *RateLimitConfig) *rateLimiter {
+	if cfg == nil {
+		return nil
+	}
+	l := &rateLimiter{}
+	if cfg.RequestsPerSecond > 0 {
+		burst := cfg.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		l.limiter = rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), burst)
+	}
+	if cfg.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return l
+}
+
+// acquire waits for an in-flight slot and for the rate limit to allow the first attempt of
+// a request. The returned func releases the slot, it must be called once the request is done.
+// The retries of the request wait for the rate limit with wait.
+func (l *rateLimiter) acquire(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l == nil {
+		return release, nil
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if err := l.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// wait waits for the rate limit to allow an attempt to send a request.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil || l.limiter == nil {
+		return nil
+	}
+	err := l.limiter.Wait(ctx)
+	if err != nil && ctx.Err() == nil {
+		// Wait fails early if the deadline of ctx is too close, report it as expired.
+		err = context.DeadlineExceeded
+	}
+	return err
+}
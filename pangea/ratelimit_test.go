@@ -0,0 +1,132 @@
+package pangea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, `{"request_id": "some-id", "status_code": 200, "status": "Success", "result": null}`)
+}
+
+func TestDo_With_MaxInFlight_Limits_Concurrent_Requests(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	var inFlight, maxInFlight int32
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		okHandler(w, r)
+	})
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.RateLimits = map[string]*pangea.RateLimitConfig{
+		"service": {MaxInFlight: 2},
+	}
+	client := pangea.NewClient("service", cfg)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := client.NewRequest("POST", "test", nil)
+			_, err := client.Do(context.Background(), req, nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxInFlight)
+}
+
+func TestDo_With_RequestsPerSecond_Limits_Request_Rate(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", okHandler)
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.RateLimits = map[string]*pangea.RateLimitConfig{
+		"service": {RequestsPerSecond: 50, Burst: 1},
+		"other":   {RequestsPerSecond: 0.001},
+	}
+	client := pangea.NewClient("service", cfg)
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		req, _ := client.NewRequest("POST", "test", nil)
+		_, err := client.Do(context.Background(), req, nil)
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestDo_With_RateLimit_Returns_Context_Error_While_Waiting(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", okHandler)
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.RateLimits = map[string]*pangea.RateLimitConfig{
+		"service": {RequestsPerSecond: 0.001, Burst: 1},
+	}
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ = client.NewRequest("POST", "test", nil)
+	_, err = client.Do(ctx, req, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDo_With_RequestsPerSecond_Limits_Retries(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	var attempts int32
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) <= 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		okHandler(w, r)
+	})
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Retry = true
+	cfg.RetryConfig = &pangea.RetryConfig{RetryMax: 3, RetryWaitMin: time.Millisecond, RetryWaitMax: time.Millisecond}
+	cfg.RateLimits = map[string]*pangea.RateLimitConfig{
+		"service": {RequestsPerSecond: 20, Burst: 1},
+	}
+	client := pangea.NewClient("service", cfg)
+
+	start := time.Now()
+	req, _ := client.NewRequest("GET", "test", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, resp.Attempts)
+	// The 3 retries wait for the rate limit, not only the first attempt.
+	assert.GreaterOrEqual(t, time.Since(start), 140*time.Millisecond)
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ResponseHeader struct {
@@ -31,6 +34,111 @@ type Response struct {
 	HTTPResponse *http.Response
 	// Query raw result
 	RawResult json.RawMessage `json:"result"`
+
+	// The number of attempts made to send the request, including retries and, with
+	// PollAccepted, the polls for its result
+	Attempts int `json:"-"`
+
+	// The idempotency key sent with the request, the same for all the attempts.
+	// It is empty if the request had none.
+	IdempotencyKey string `json:"-"`
+
+	// The time from sending the request to reading the response, including the retries and,
+	// with PollAccepted, the polls for its result
+	Latency time.Duration `json:"-"`
+
+	// The timings of the attempts made to send the request, then to poll for its result, in order
+	AttemptTimings []AttemptTiming `json:"-"`
+
+	// The size of the request body in bytes, -1 if unknown
+	RequestSize int64 `json:"-"`
+
+	// The raw body of the response, as HTTPResponse.Body is consumed
+	RawBody []byte `json:"-"`
+
+	// The metrics of the Server-Timing header of the response
+	ServerTimings []ServerTiming `json:"-"`
+}
+
+// Retries returns the number of times the request was retried.
+func (r *Response) Retries() int {
+	if r.Attempts < 1 {
+		return 0
+	}
+	return r.Attempts - 1
+}
+
+// AttemptTiming is the timing of an attempt to send a request.
+type AttemptTiming struct {
+	// The time the attempt started
+	Start time.Time
+
+	// The time until the response header was received or the attempt failed
+	Duration time.Duration
+
+	// The HTTP status code of the response, 0 if the attempt failed
+	StatusCode int
+
+	// The error of the failed attempt
+	Err error
+}
+
+// ServerTiming is a metric of the Server-Timing header, e.g. `db;dur=53;desc="Database"`.
+type ServerTiming struct {
+	Name        string
+	Duration    time.Duration
+	Description string
+}
+
+// parseServerTimings returns the metrics of the Server-Timing headers, skipping the malformed ones.
+func parseServerTimings(h http.Header) []ServerTiming {
+	var timings []ServerTiming
+	for _, v := range h.Values("Server-Timing") {
+		for _, metric := range splitUnquoted(v, ',') {
+			params := splitUnquoted(metric, ';')
+			timing := ServerTiming{Name: strings.TrimSpace(params[0])}
+			if timing.Name == "" {
+				continue
+			}
+			for _, param := range params[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				value = strings.TrimSpace(value)
+				if unquoted, err := strconv.Unquote(value); err == nil {
+					value = unquoted
+				}
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "dur":
+					if ms, err := strconv.ParseFloat(value, 64); err == nil {
+						timing.Duration = time.Duration(ms * float64(time.Millisecond))
+					}
+				case "desc":
+					timing.Description = value
+				}
+			}
+			timings = append(timings, timing)
+		}
+	}
+	return timings
+}
+
+// splitUnquoted splits s around sep, except inside double quoted strings.
+func splitUnquoted(s string, sep byte) []string {
+	var parts []string
+	quoted, escaped, start := false, false, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
 }
 
 func (r *ResponseHeader) String() string {
@@ -1,9 +1,15 @@
 package pangea_test
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
 	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestResponseHeader_String(t *testing.T) {
@@ -34,3 +40,62 @@ func TestResponseHeader_String_When_ResponseHeader_Is_Nil_Return_EmptyString(t *
 		t.Errorf("got %v, want empty string", got)
 	}
 }
+
+func TestDo_Sets_Response_Metadata(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	body := `{"request_id": "some-id", "status_code": 200, "status": "Success", "result": null}`
+	var calls int
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"request_id": "some-id", "status_code": 503, "status": "ServiceNotAvailable", "result": null}`)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Server-Timing", `db;dur=53.5;desc="Database, primary", total;dur=60`)
+		fmt.Fprint(w, body)
+	})
+
+	client := retryClient(t, url)
+	req, _ := client.NewRequest("POST", "test", map[string]string{"key": "value"})
+	resp, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, resp.Attempts)
+	assert.Equal(t, 1, resp.Retries())
+	if assert.Len(t, resp.AttemptTimings, 2) {
+		assert.Equal(t, http.StatusServiceUnavailable, resp.AttemptTimings[0].StatusCode)
+		assert.Equal(t, http.StatusOK, resp.AttemptTimings[1].StatusCode)
+		assert.GreaterOrEqual(t, resp.AttemptTimings[1].Duration, 10*time.Millisecond)
+		assert.True(t, resp.AttemptTimings[1].Start.After(resp.AttemptTimings[0].Start))
+	}
+	assert.GreaterOrEqual(t, resp.Latency, resp.AttemptTimings[1].Duration)
+	assert.Equal(t, int64(len(`{"key":"value"}`+"\n")), resp.RequestSize)
+	assert.Equal(t, body, string(resp.RawBody))
+	assert.Equal(t, []pangea.ServerTiming{
+		{Name: "db", Duration: 53500 * time.Microsecond, Description: "Database, primary"},
+		{Name: "total", Duration: 60 * time.Millisecond},
+	}, resp.ServerTimings)
+}
+
+func TestDo_With_HTTPClient_Sets_Single_Attempt_Timing(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+	mux.HandleFunc("/test", okHandler)
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.HTTPClient = &http.Client{}
+	client := pangea.NewClient("service", cfg)
+	req, _ := client.NewRequest("POST", "test", nil)
+	resp, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, resp.Retries())
+	assert.Len(t, resp.AttemptTimings, 1)
+	assert.Equal(t, resp.Latency, resp.AttemptTimings[0].Duration)
+	assert.Equal(t, int64(0), resp.RequestSize)
+	assert.Empty(t, resp.ServerTimings)
+}
@@ -0,0 +1,158 @@
+package pangea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// IdempotencyKeyHeader is the header carrying the idempotency key of a request.
+// Requests to non-idempotent endpoints are only retried when they carry one.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// retryableStatuses are the Pangea statuses of transient failures.
+var retryableStatuses = map[string]bool{
+	"TooManyRequests":     true,
+	"ServiceNotAvailable": true,
+	"InternalError":       true,
+}
+
+// requestState is shared through the request context between Client.send and the HTTP client.
+type requestState struct {
+	// the service and endpoint path of the request
+	service, path string
+
+	// retry is false if the request must not be retried
+	retry bool
+
+	// limiter limits the rate of the retries, the first attempt is limited by Client.send
+	limiter *rateLimiter
+
+	// the number of attempts made to send the request
+	attempts int32
+
+	mu      sync.Mutex
+	timings []AttemptTiming
+}
+
+func (s *requestState) addTiming(timing AttemptTiming) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timings = append(s.timings, timing)
+}
+
+func (s *requestState) attemptTimings() []AttemptTiming {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]AttemptTiming(nil), s.timings...)
+}
+
+type requestStateKey struct{}
+
+func withRequestState(ctx context.Context, state *requestState) context.Context {
+	return context.WithValue(ctx, requestStateKey{}, state)
+}
+
+func requestStateFrom(ctx context.Context) *requestState {
+	state, _ := ctx.Value(requestStateKey{}).(*requestState)
+	return state
+}
+
+// attemptTransport counts and times the attempts made to send a request, and waits for the
+// rate limit of the client before the retries.
+type attemptTransport struct {
+	base http.RoundTripper
+}
+
+func (t *attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	state := requestStateFrom(req.Context())
+	if state == nil {
+		return t.base.RoundTrip(req)
+	}
+	if atomic.AddInt32(&state.attempts, 1) > 1 {
+		if err := state.limiter.wait(req.Context()); err != nil {
+			closeRequestBody(req)
+			return nil, err
+		}
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	timing := AttemptTiming{Start: start, Duration: time.Since(start), Err: err}
+	if resp != nil {
+		timing.StatusCode = resp.StatusCode
+	}
+	state.addTiming(timing)
+	return resp, err
+}
+
+// retryPolicy retries connection errors, 429 and 5xx responses and responses with a transient
+// Pangea status, unless the request is not idempotent and has no idempotency key.
+func retryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if state := requestStateFrom(ctx); state != nil && !state.retry {
+		return false, nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// The rate limit would not allow a retry before the deadline.
+		return false, err
+	}
+	if err != nil {
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true, nil
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return retryableStatuses[peekStatus(resp)], nil
+	}
+	return false, nil
+}
+
+// peekStatus returns the Pangea status of the response, leaving its body unread.
+func peekStatus(resp *http.Response) string {
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return ""
+	}
+	var header ResponseHeader
+	if json.Unmarshal(b, &header) != nil {
+		return ""
+	}
+	return StringValue(header.Status)
+}
+
+// retryBackoff waits as long as requested by the Retry-After header of 429 and 503 responses,
+// otherwise it backs off exponentially.
+func retryBackoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait := retryAfter(resp.Header); wait > 0 {
+			return wait
+		}
+	}
+	return retryablehttp.DefaultBackoff(min, max, attemptNum, resp)
+}
+
+// closeRequestBody closes the body of a request not sent, as required from a RoundTripper.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,130 @@
+package pangea_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func retryClient(t *testing.T, url string) *pangea.Client {
+	t.Helper()
+	cfg := pangeatesting.TestConfig(url)
+	cfg.Retry = true
+	cfg.RetryConfig = &pangea.RetryConfig{
+		RetryMax:     3,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+	}
+	return pangea.NewClient("service", cfg)
+}
+
+// failingHandler fails the first n requests with the given status code and body.
+func failingHandler(n *int, code int, headers map[string]string, body string) http.HandlerFunc {
+	var calls int
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		*n = calls
+		if calls <= 2 {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			w.WriteHeader(code)
+			fmt.Fprint(w, body)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 200, "status": "Success", "result": null}`)
+	}
+}
+
+func TestDo_With_Retries_Retries_Transient_Failures_And_Counts_Attempts(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		body string
+	}{
+		{"503", http.StatusServiceUnavailable, ``},
+		{"429", http.StatusTooManyRequests, `{"status": "TooManyRequests"}`},
+		{"ServiceNotAvailable status", http.StatusBadRequest, `{"status": "ServiceNotAvailable"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, url, teardown := pangeatesting.SetupServer()
+			defer teardown()
+
+			var calls int
+			mux.HandleFunc("/test", failingHandler(&calls, tt.code, nil, tt.body))
+
+			client := retryClient(t, url)
+			req, _ := client.NewRequest("POST", "test", map[string]string{"key": "value"})
+			resp, err := client.Do(context.Background(), req, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, calls)
+			assert.Equal(t, 3, resp.Attempts)
+		})
+	}
+}
+
+func TestDo_With_Retries_Does_Not_Retry_Validation_Errors(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/test", failingHandler(&calls, http.StatusBadRequest, nil,
+		`{"request_id": "some-id", "status_code": 400, "status": "ValidationError", "result": null}`))
+
+	client := retryClient(t, url)
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+
+	assert.ErrorIs(t, err, pangea.ErrValidation)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_With_Retries_Honors_Retry_After(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/test", failingHandler(&calls, http.StatusTooManyRequests, map[string]string{"Retry-After": "1"}, ``))
+
+	client := retryClient(t, url)
+	req, _ := client.NewRequest("POST", "test", nil)
+	start := time.Now()
+	_, err := client.Do(context.Background(), req, nil)
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 2*time.Second)
+}
+
+func TestDo_With_Retries_Does_Not_Retry_NonIdempotent_Requests_Without_Idempotency_Key(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/v1/log", failingHandler(&calls, http.StatusServiceUnavailable, nil,
+		`{"request_id": "some-id", "status_code": 503, "status": "ServiceNotAvailable", "result": null}`))
+
+	client := retryClient(t, url)
+	client.NonIdempotentPaths = []string{"v1/log"}
+	client.Config.DisableIdempotencyKeys = true
+
+	req, _ := client.NewRequest("POST", "v1/log", nil)
+	_, err := client.Do(context.Background(), req, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	req, _ = client.NewRequest("POST", "v1/log", nil)
+	req.Header.Set(pangea.IdempotencyKeyHeader, "some-key")
+	resp, err := client.Do(context.Background(), req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, resp.Attempts)
+}
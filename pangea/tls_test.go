@@ -0,0 +1,136 @@
+package pangea_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/embargo"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, name, blockType string, b []byte) string {
+	name = filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), 0o600)
+	assert.NoError(t, err)
+	return name
+}
+
+// newTLSServer starts a TLS server, configured by configure if not nil, and returns a config
+// sending requests to it and the CA bundle trusting it.
+func newTLSServer(t *testing.T, configure func(*tls.Config)) (cfg *pangea.Config, caFile string) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(okHandler))
+	srv.TLS = &tls.Config{}
+	if configure != nil {
+		configure(srv.TLS)
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	cfg = &pangea.Config{
+		Token:      "TestToken",
+		Domain:     strings.TrimPrefix(srv.URL, "https://"),
+		Enviroment: "local",
+	}
+	return cfg, writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+}
+
+func TestDo_With_CABundle_Trusts_Server(t *testing.T) {
+	cfg, caFile := newTLSServer(t, nil)
+	err := doTestRequest(pangea.NewClient("service", cfg))
+	var certErr *tls.CertificateVerificationError
+	assert.ErrorAs(t, err, &certErr)
+
+	cfg.CABundleFiles = []string{caFile}
+	for _, retry := range []bool{false, true} {
+		cfg.Retry = retry
+		assert.NoError(t, doTestRequest(pangea.NewClient("service", cfg)))
+	}
+}
+
+func TestDo_With_ClientCert_Authenticates_To_Server(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	cfg, caFile := newTLSServer(t, func(c *tls.Config) {
+		c.ClientAuth = tls.RequireAnyClientCert
+	})
+	cfg.CABundleFiles = []string{caFile}
+	assert.Error(t, doTestRequest(pangea.NewClient("service", cfg)))
+
+	cfg.ClientCertFile = writePEM(t, "client.pem", "CERTIFICATE", certDER)
+	cfg.ClientKeyFile = writePEM(t, "client.key", "EC PRIVATE KEY", keyDER)
+	assert.NoError(t, doTestRequest(pangea.NewClient("service", cfg)))
+}
+
+func TestDo_With_TLSMinVersion_Refuses_Older_Versions(t *testing.T) {
+	cfg, caFile := newTLSServer(t, func(c *tls.Config) {
+		c.MaxVersion = tls.VersionTLS12
+	})
+	cfg.CABundleFiles = []string{caFile}
+	assert.NoError(t, doTestRequest(pangea.NewClient("service", cfg)))
+
+	cfg.TLSMinVersion = tls.VersionTLS13
+	assert.ErrorContains(t, doTestRequest(pangea.NewClient("service", cfg)), "protocol version")
+}
+
+func TestDo_With_ProxyURL_Sends_Requests_Through_Proxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		okHandler(w, r)
+	}))
+	defer proxy.Close()
+
+	client := pangea.NewClient("service", &pangea.Config{
+		Token:    "TestToken",
+		Domain:   "pangea.test",
+		Insecure: true,
+		ProxyURL: proxy.URL,
+	})
+	assert.NoError(t, doTestRequest(client))
+	assert.Equal(t, []string{"http://service.pangea.test/test"}, proxied)
+}
+
+func TestNew_With_Invalid_TLS_Config_Returns_Error(t *testing.T) {
+	cfg := &pangea.Config{Token: "TestToken", Domain: "pangea.test"}
+
+	cfg.CABundleFiles = []string{filepath.Join(t.TempDir(), "missing.pem")}
+	_, err := embargo.New(cfg)
+	assert.ErrorContains(t, err, "cannot read CA bundle")
+
+	cfg.CABundleFiles = nil
+	cfg.ClientCertFile = "client.pem"
+	client := pangea.NewClient("service", cfg)
+	assert.ErrorContains(t, client.Err(), "cannot load client certificate")
+	assert.ErrorIs(t, doTestRequest(client), client.Err())
+
+	cfg.ClientCertFile = ""
+	cfg.ProxyURL = "proxy:3128"
+	_, err = embargo.New(cfg)
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
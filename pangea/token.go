@@ -0,0 +1,164 @@
+package pangea
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenProvider provides the token used to authenticate requests.
+// It is consulted by NewRequest for every request, so it must be safe for concurrent use.
+type TokenProvider interface {
+	Token() (string, error)
+}
+
+// TokenRefresher is implemented by the token providers that can refresh their token on demand.
+// When Pangea rejects a request as unauthorized (401), the client refreshes the token and
+// retries the request once.
+type TokenRefresher interface {
+	Refresh() error
+}
+
+// StaticTokenProvider always provides the same token.
+type StaticTokenProvider string
+
+func (p StaticTokenProvider) Token() (string, error) {
+	return string(p), nil
+}
+
+// EnvTokenProvider provides the token in the named environment variable, read on every request.
+type EnvTokenProvider string
+
+func (p EnvTokenProvider) Token() (string, error) {
+	token := os.Getenv(string(p))
+	if token == "" {
+		return "", fmt.Errorf("pangea: environment variable %v is empty", string(p))
+	}
+	return token, nil
+}
+
+// FileTokenProvider provides the token stored in a file, e.g. a mounted secret.
+// The file is read again when its modification time or size change.
+type FileTokenProvider struct {
+	// The name of the file with the token
+	Name string
+
+	// Minimum time between checks of the file for changes
+	CheckInterval time.Duration
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+	checked time.Time
+}
+
+func NewFileTokenProvider(name string) *FileTokenProvider {
+	return &FileTokenProvider{
+		Name:          name,
+		CheckInterval: time.Second,
+	}
+}
+
+func (p *FileTokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && time.Since(p.checked) < p.CheckInterval {
+		return p.token, nil
+	}
+	err := p.load(false)
+	if err != nil {
+		return "", err
+	}
+	return p.token, nil
+}
+
+// Refresh reads the file again, even if it did not change.
+func (p *FileTokenProvider) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.load(true)
+}
+
+func (p *FileTokenProvider) load(force bool) error {
+	info, err := os.Stat(p.Name)
+	if err != nil {
+		return fmt.Errorf("pangea: cannot read token file: %w", err)
+	}
+	p.checked = time.Now()
+	if !force && p.token != "" && info.ModTime().Equal(p.modTime) && info.Size() == p.size {
+		return nil
+	}
+
+	b, err := os.ReadFile(p.Name)
+	if err != nil {
+		return fmt.Errorf("pangea: cannot read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return fmt.Errorf("pangea: token file %v is empty", p.Name)
+	}
+	p.token = token
+	p.modTime = info.ModTime()
+	p.size = info.Size()
+	return nil
+}
+
+// CallbackTokenProvider provides the token returned by a callback, e.g. fetched from a
+// secrets manager. The token is cached until it expires.
+type CallbackTokenProvider struct {
+	// Fetch returns a new token and the time it expires at, the zero time meaning it never expires.
+	Fetch func() (token string, expiresAt time.Time, err error)
+
+	// How long before its expiration the token is refreshed
+	RefreshBefore time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewCallbackTokenProvider(fetch func() (string, time.Time, error)) *CallbackTokenProvider {
+	return &CallbackTokenProvider{
+		Fetch:         fetch,
+		RefreshBefore: 30 * time.Second,
+	}
+}
+
+func (p *CallbackTokenProvider) Token() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.token != "" && (p.expiresAt.IsZero() || time.Now().Add(p.RefreshBefore).Before(p.expiresAt)) {
+		return p.token, nil
+	}
+	err := p.fetch()
+	if err != nil {
+		return "", err
+	}
+	return p.token, nil
+}
+
+// Refresh calls Fetch for a new token, even if the cached one did not expire.
+func (p *CallbackTokenProvider) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetch()
+}
+
+func (p *CallbackTokenProvider) fetch() error {
+	token, expiresAt, err := p.Fetch()
+	if err != nil {
+		return fmt.Errorf("pangea: cannot fetch token: %w", err)
+	}
+	if token == "" {
+		return errors.New("pangea: fetched an empty token")
+	}
+	p.token = token
+	p.expiresAt = expiresAt
+	return nil
+}
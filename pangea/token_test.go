@@ -0,0 +1,131 @@
+package pangea_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/pangeatesting"
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequest_Uses_TokenProvider_On_Every_Request(t *testing.T) {
+	t.Setenv("TEST_PANGEA_TOKEN", "first")
+
+	cfg := pangeatesting.TestConfig("localhost")
+	cfg.TokenProvider = pangea.EnvTokenProvider("TEST_PANGEA_TOKEN")
+	client := pangea.NewClient("service", cfg)
+
+	req, err := client.NewRequest("POST", "test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer first", req.Header.Get("Authorization"))
+
+	t.Setenv("TEST_PANGEA_TOKEN", "second")
+	req, err = client.NewRequest("POST", "test", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer second", req.Header.Get("Authorization"))
+
+	t.Setenv("TEST_PANGEA_TOKEN", "")
+	_, err = client.NewRequest("POST", "test", nil)
+	assert.ErrorContains(t, err, "TEST_PANGEA_TOKEN")
+}
+
+func TestDo_When_Server_Returns_401_It_Refreshes_Token_And_Retries_Once(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		pangeatesting.TestBody(t, r, `{"key":"value"}`)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"request_id": "some-id", "status_code": 401, "status": "Unauthorized", "result": null}`)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 200, "status": "Success", "result": null}`)
+	})
+
+	var fetches int
+	cfg := pangeatesting.TestConfig(url)
+	cfg.TokenProvider = pangea.NewCallbackTokenProvider(func() (string, time.Time, error) {
+		fetches++
+		return fmt.Sprintf("token-%d", fetches), time.Time{}, nil
+	})
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", map[string]string{"key": "value"})
+	resp, err := client.Do(context.Background(), req, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Success", pangea.StringValue(resp.Status))
+	assert.Equal(t, 2, fetches)
+}
+
+func TestDo_When_Server_Returns_401_Without_Refreshable_Token_It_Returns_Error(t *testing.T) {
+	mux, url, teardown := pangeatesting.SetupServer()
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"request_id": "some-id", "status_code": 401, "status": "Unauthorized", "result": null}`)
+	})
+
+	cfg := pangeatesting.TestConfig(url)
+	cfg.TokenProvider = pangea.StaticTokenProvider("token")
+	client := pangea.NewClient("service", cfg)
+
+	req, _ := client.NewRequest("POST", "test", nil)
+	_, err := client.Do(context.Background(), req, nil)
+
+	var apiErr *pangea.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 1, calls)
+}
+
+func TestFileTokenProvider_Reloads_Changed_File(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(name, []byte("first\n"), 0o600))
+
+	p := pangea.NewFileTokenProvider(name)
+	p.CheckInterval = 0
+
+	token, err := p.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "first", token)
+
+	assert.NoError(t, os.WriteFile(name, []byte("second-token\n"), 0o600))
+	token, err = p.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+
+	assert.NoError(t, os.Remove(name))
+	_, err = p.Token()
+	assert.Error(t, err)
+}
+
+func TestCallbackTokenProvider_Refreshes_Expired_Token(t *testing.T) {
+	var fetches int
+	expiresAt := time.Now().Add(time.Hour)
+	p := pangea.NewCallbackTokenProvider(func() (string, time.Time, error) {
+		fetches++
+		return fmt.Sprintf("token-%d", fetches), expiresAt, nil
+	})
+
+	token, _ := p.Token()
+	assert.Equal(t, "token-1", token)
+	token, _ = p.Token()
+	assert.Equal(t, "token-1", token)
+
+	expiresAt = time.Now()
+	assert.NoError(t, p.Refresh())
+	token, _ = p.Token()
+	assert.Equal(t, "token-3", token)
+}
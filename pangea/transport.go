@@ -0,0 +1,141 @@
+package pangea
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/defaults"
+)
+
+// TransportConfig tunes the pooled transport keeping the connections to Pangea alive between
+// requests. The zero values keep the defaults of defaults.HTTPPooledTransport.
+type TransportConfig struct {
+	MaxIdleConns        int           // Maximum number of idle connections, defaults to 100
+	MaxIdleConnsPerHost int           // Maximum number of idle connections per host, defaults to GOMAXPROCS+1
+	MaxConnsPerHost     int           // Maximum number of connections per host, 0 means no limit
+	IdleConnTimeout     time.Duration // Time an idle connection is kept open, defaults to 90s
+	TLSHandshakeTimeout time.Duration // Maximum time to wait for a TLS handshake, defaults to 10s
+	DisableHTTP2        bool          // Set to true to only use HTTP/1.1
+}
+
+// NewPooledTransport returns a transport keeping the connections alive, tuned by cfg if not nil.
+// It can be shared by the clients of several services with Config.Transport.
+func NewPooledTransport(cfg *TransportConfig) *http.Transport {
+	transport := defaults.HTTPPooledTransport()
+	if cfg == nil {
+		return transport
+	}
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		// A non-nil empty map disables HTTP/2.
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
+
+// NewTransport returns a pooled transport with the TLS and proxy settings of the config,
+// tuned by PooledTransport. It can be shared by the clients of several services with Transport.
+func (c *Config) NewTransport() (http.RoundTripper, error) {
+	cfg := *c
+	cfg.Transport = nil
+	if cfg.PooledTransport == nil {
+		cfg.PooledTransport = &TransportConfig{}
+	}
+	return newTransport(&cfg, true)
+}
+
+// newTransport returns the transport of the HTTP client built from cfg, with its TLS and
+// proxy settings. retry tells if the client retries, retries keep the connections alive.
+func newTransport(cfg *Config, retry bool) (http.RoundTripper, error) {
+	if cfg.Transport != nil {
+		return cfg.Transport, nil
+	}
+	var transport *http.Transport
+	switch {
+	case cfg.PooledTransport != nil:
+		transport = NewPooledTransport(cfg.PooledTransport)
+	case retry:
+		transport = defaults.HTTPPooledTransport()
+	default:
+		transport = defaults.HTTPTransport()
+	}
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("pangea: invalid proxy URL %q", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// newTLSConfig returns the TLS config set by cfg, nil if the defaults are kept.
+func newTLSConfig(cfg *Config) (*tls.Config, error) {
+	if len(cfg.CABundleFiles) == 0 && cfg.ClientCertFile == "" && cfg.ClientKeyFile == "" && cfg.TLSMinVersion == 0 {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if cfg.TLSMinVersion != 0 {
+		if cfg.TLSMinVersion < tls.VersionTLS10 || cfg.TLSMinVersion > tls.VersionTLS13 {
+			return nil, fmt.Errorf("pangea: invalid TLS version %#x", cfg.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = cfg.TLSMinVersion
+	}
+
+	if len(cfg.CABundleFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, name := range cfg.CABundleFiles {
+			pem, err := os.ReadFile(name)
+			if err != nil {
+				return nil, fmt.Errorf("pangea: cannot read CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("pangea: no certificate found in CA bundle %v", name)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("pangea: cannot load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
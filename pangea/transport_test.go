@@ -0,0 +1,96 @@
+package pangea_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCountingServer returns a server counting the connections opened to it.
+func newCountingServer(tb testing.TB) (cfg *pangea.Config, conns *int32) {
+	conns = new(int32)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(okHandler))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(conns, 1)
+		}
+	}
+	srv.Start()
+	tb.Cleanup(srv.Close)
+
+	cfg = &pangea.Config{
+		Token:      "TestToken",
+		Domain:     strings.TrimPrefix(srv.URL, "http://"),
+		Insecure:   true,
+		Enviroment: "local",
+	}
+	return cfg, conns
+}
+
+func doTestRequests(tb testing.TB, client *pangea.Client, n int) {
+	for i := 0; i < n; i++ {
+		req, _ := client.NewRequest("POST", "test", nil)
+		if _, err := client.Do(context.Background(), req, nil); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestDo_With_PooledTransport_Reuses_Connections(t *testing.T) {
+	cfg, conns := newCountingServer(t)
+	doTestRequests(t, pangea.NewClient("service", cfg), 5)
+	assert.Equal(t, int32(5), atomic.LoadInt32(conns))
+
+	cfg, conns = newCountingServer(t)
+	cfg.PooledTransport = &pangea.TransportConfig{MaxIdleConnsPerHost: 1}
+	doTestRequests(t, pangea.NewClient("service", cfg), 5)
+	assert.Equal(t, int32(1), atomic.LoadInt32(conns))
+}
+
+func TestNewPooledTransport_Applies_Config(t *testing.T) {
+	transport := pangea.NewPooledTransport(&pangea.TransportConfig{
+		MaxIdleConns:    10,
+		MaxConnsPerHost: 4,
+		DisableHTTP2:    true,
+	})
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 4, transport.MaxConnsPerHost)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+	assert.True(t, pangea.NewPooledTransport(nil).ForceAttemptHTTP2)
+}
+
+func BenchmarkDo_Connections(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		cfg  *pangea.TransportConfig
+	}{
+		{"New connection per request", nil},
+		{"Pooled connections", &pangea.TransportConfig{}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			cfg, conns := newCountingServer(b)
+			cfg.PooledTransport = bm.cfg
+			client := pangea.NewClient("service", cfg)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					req, _ := client.NewRequest("POST", "test", nil)
+					if _, err := client.Do(context.Background(), req, nil); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+			b.ReportMetric(float64(atomic.LoadInt32(conns)), "conns")
+		})
+	}
+}
@@ -3,7 +3,10 @@ package audit
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pangeacyber/go-pangea/internal/pangeautil"
@@ -53,6 +56,85 @@ func (a *Audit) Log(ctx context.Context, input *LogInput) (*pangea.PangeaRespons
 	return &panresp, nil
 }
 
+// defaultLogBatchConcurrency is the number of events of a batch logged concurrently by default.
+const defaultLogBatchConcurrency = 8
+
+// Log a batch of entries
+//
+// Create the log entries of a batch in the Secure Audit Log, pipelining the requests.
+// The events are signed if SignLogs is set and each event is logged once even if retried,
+// with its own idempotency key. If ctx has a key set with pangea.WithIdempotencyKey, the key
+// of each event is derived from it and the index of the event, e.g. "key/0", so the batch can
+// be logged again with the same ctx without duplicating its events. The result of each event
+// is returned, so the events that failed can be logged again. An error is only returned if the
+// batch is invalid. The batch response has no request ID, the response of each event is in its
+// result.
+//
+// Example:
+//
+//	input := &audit.LogBatchInput{
+//		Events: []*audit.LogInput{
+//			{Event: &audit.Event{Message: pangea.String("first message")}},
+//			{Event: &audit.Event{Message: pangea.String("second message")}},
+//		},
+//	}
+//
+//	batchResponse, err := auditcli.LogBatch(ctx, input)
+//	err = batchResponse.Result.Err()
+func (a *Audit) LogBatch(ctx context.Context, input *LogBatchInput) (*pangea.PangeaResponse[LogBatchOutput], error) {
+	if input == nil {
+		return nil, errors.New("audit: nil batch")
+	}
+	concurrency := input.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultLogBatchConcurrency
+	}
+
+	batchKey, hasBatchKey := pangea.IdempotencyKeyFromContext(ctx)
+	out := LogBatchOutput{Results: make([]LogBatchResult, len(input.Events))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, event := range input.Events {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			out.Results[i].Err = ctx.Err()
+			continue
+		}
+		eventCtx := ctx
+		if hasBatchKey {
+			eventCtx = pangea.WithIdempotencyKey(ctx, batchKey+"/"+strconv.Itoa(i))
+		}
+		wg.Add(1)
+		go func(ctx context.Context, result *LogBatchResult, event *LogInput) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if event == nil || event.Event == nil {
+				result.Err = errors.New("audit: nil event")
+				return
+			}
+			resp, err := a.Log(ctx, event)
+			if err != nil {
+				result.Err = err
+				return
+			}
+			result.Output = resp.Result
+			result.Response = &resp.Response
+		}(eventCtx, &out.Results[i], event)
+	}
+	wg.Wait()
+
+	return &pangea.PangeaResponse[LogBatchOutput]{
+		Response: pangea.Response{
+			ResponseHeader: pangea.ResponseHeader{
+				Status:  pangea.String("Success"),
+				Summary: pangea.String(fmt.Sprintf("Logged %v record(s), %v failed", len(out.Results)-out.Failed(), out.Failed())),
+			},
+		},
+		Result: &out,
+	}, nil
+}
+
 // Search for events
 //
 // Search for events that match the provided search criteria.
@@ -221,6 +303,53 @@ type LogInput struct {
 	PublicKey *string `json:"public_key,omitempty"`
 }
 
+type LogBatchInput struct {
+	// The events to log
+	Events []*LogInput
+
+	// The maximum number of events logged concurrently, defaults to 8
+	Concurrency int
+}
+
+type LogBatchOutput struct {
+	// The results of the events, in the order of the input
+	Results []LogBatchResult
+}
+
+// LogBatchResult is the result of logging an event of a batch.
+type LogBatchResult struct {
+	// The output of the log, nil if it failed
+	Output *LogOutput
+
+	// The response of the log, nil if it failed
+	Response *pangea.Response
+
+	// The error of the log
+	Err error
+}
+
+// Failed returns the number of events that failed to be logged.
+func (o *LogBatchOutput) Failed() int {
+	n := 0
+	for _, r := range o.Results {
+		if r.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns the errors of the events that failed to be logged, nil if all were logged.
+func (o *LogBatchOutput) Err() error {
+	var errs []error
+	for i, r := range o.Results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("audit: event %v: %w", i, r.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (i *LogInput) Sign(s signer.Signer) error {
 	b, err := newsSignedMessageFromRecord(i.Event.Actor, i.Event.Action, i.Event.Message, i.Event.New,
 		i.Event.Old, i.Event.Source, i.Event.Status, i.Event.Target, i.Event.Timestamp)
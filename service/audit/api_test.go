@@ -362,8 +362,11 @@ func TestRoot(t *testing.T) {
 
 func TestLogError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := audit.New(cfg)
-		_, err := client.Log(context.Background(), nil)
+		client, err := audit.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.Log(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Audit.Log", f)
@@ -371,8 +374,11 @@ func TestLogError(t *testing.T) {
 
 func TestSearchError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := audit.New(cfg)
-		_, err := client.Search(context.Background(), nil)
+		client, err := audit.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.Search(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Audit.Search", f)
@@ -380,8 +386,11 @@ func TestSearchError(t *testing.T) {
 
 func TestSearchResultsError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := audit.New(cfg)
-		_, err := client.SearchResults(context.Background(), nil)
+		client, err := audit.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.SearchResults(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Audit.SearchResults", f)
@@ -389,8 +398,11 @@ func TestSearchResultsError(t *testing.T) {
 
 func TestRootError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := audit.New(cfg)
-		_, err := client.Root(context.Background(), nil)
+		client, err := audit.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.Root(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Audit.Root", f)
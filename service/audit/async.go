@@ -0,0 +1,360 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is the error of the events dropped because the queue of an AsyncLogger is full.
+	ErrQueueFull = errors.New("audit: queue full")
+
+	// ErrLoggerClosed is the error of the events logged after an AsyncLogger is closed.
+	ErrLoggerClosed = errors.New("audit: logger closed")
+)
+
+// BackpressurePolicy is what an AsyncLogger does with the events logged when its queue is full.
+type BackpressurePolicy int
+
+const (
+	// Block waits for room in the queue, until the context of Log is done.
+	Block BackpressurePolicy = iota
+
+	// DropOldest drops the oldest event of the queue to make room.
+	DropOldest
+
+	// DropNewest drops the logged event.
+	DropNewest
+)
+
+// AsyncLoggerConfig configures an AsyncLogger, the zero values are replaced by the defaults.
+type AsyncLoggerConfig struct {
+	QueueSize     int           // Maximum number of events waiting to be sent, defaults to 1024
+	Workers       int           // Number of goroutines sending the events, defaults to 2
+	BatchSize     int           // Maximum number of events sent together by a worker, defaults to 64
+	FlushInterval time.Duration // Maximum time an event waits for a batch to fill, defaults to 1s
+
+	// What to do with the events logged when the queue is full, defaults to Block
+	Backpressure BackpressurePolicy
+
+	// OnDrop is called with the events dropped and the reason, ErrQueueFull or ErrLoggerClosed
+	OnDrop func(input *LogInput, err error)
+
+	// OnError is called with the events Pangea failed to log and the error
+	OnError func(input *LogInput, err error)
+}
+
+var defaultAsyncLoggerConfig = AsyncLoggerConfig{
+	QueueSize:     1024,
+	Workers:       2,
+	BatchSize:     64,
+	FlushInterval: time.Second,
+}
+
+// AsyncLogger logs the events in the background, so callers don't wait for Pangea.
+// The events are queued and sent in batches with LogBatch by worker goroutines.
+// Close must be called to send the queued events and stop the workers.
+type AsyncLogger struct {
+	client Client
+	cfg    AsyncLoggerConfig
+	queue  chan queuedEvent
+	wg     sync.WaitGroup
+
+	// sendCtx is canceled when Close gives up waiting for the events to be sent
+	sendCtx    context.Context
+	cancelSend context.CancelFunc
+
+	// closing is closed when Close is called, closeMu guards sending to queue against its closing
+	closing   chan struct{}
+	closeOnce sync.Once
+	closeMu   sync.RWMutex
+
+	mu       sync.Mutex
+	pending  int           // events queued or being sent
+	seq      uint64        // sequence number of the last event logged
+	flushes  []*flush      // calls to Flush waiting for their events
+	flushers int           // calls to Flush in progress
+	flushing chan struct{} // closed while flushers > 0
+}
+
+// queuedEvent is an event queued with its sequence number.
+type queuedEvent struct {
+	input *LogInput
+	seq   uint64
+}
+
+// flush is a call to Flush waiting for the events logged before it.
+type flush struct {
+	seq     uint64        // sequence number of the last event logged before the call
+	pending int           // events up to seq queued or being sent
+	done    chan struct{} // closed when pending is 0
+}
+
+// NewAsyncLogger returns an AsyncLogger sending the events with client and starts its workers.
+func NewAsyncLogger(client Client, cfg *AsyncLoggerConfig) *AsyncLogger {
+	c := defaultAsyncLoggerConfig
+	if cfg != nil {
+		c.Backpressure = cfg.Backpressure
+		c.OnDrop = cfg.OnDrop
+		c.OnError = cfg.OnError
+		if cfg.QueueSize > 0 {
+			c.QueueSize = cfg.QueueSize
+		}
+		if cfg.Workers > 0 {
+			c.Workers = cfg.Workers
+		}
+		if cfg.BatchSize > 0 {
+			c.BatchSize = cfg.BatchSize
+		}
+		if cfg.FlushInterval > 0 {
+			c.FlushInterval = cfg.FlushInterval
+		}
+	}
+
+	l := &AsyncLogger{
+		client:   client,
+		cfg:      c,
+		queue:    make(chan queuedEvent, c.QueueSize),
+		closing:  make(chan struct{}),
+		flushing: make(chan struct{}),
+	}
+	l.sendCtx, l.cancelSend = context.WithCancel(context.Background())
+	for i := 0; i < c.Workers; i++ {
+		l.wg.Add(1)
+		go l.work()
+	}
+	return l
+}
+
+// Log queues the event to be logged. The event must not be modified afterwards.
+// It returns ErrLoggerClosed after Close, ErrQueueFull if the queue is full with the DropNewest
+// policy and the error of ctx if it is done while waiting for room with the Block policy.
+// The errors of Pangea are reported to OnError.
+func (l *AsyncLogger) Log(ctx context.Context, input *LogInput) error {
+	l.closeMu.RLock()
+	defer l.closeMu.RUnlock()
+	select {
+	case <-l.closing:
+		l.drop(input, ErrLoggerClosed)
+		return ErrLoggerClosed
+	default:
+	}
+
+	event := queuedEvent{input: input, seq: l.add()}
+	for {
+		select {
+		case l.queue <- event:
+			return nil
+		default:
+		}
+
+		switch l.cfg.Backpressure {
+		case DropNewest:
+			l.done(event.seq)
+			l.drop(input, ErrQueueFull)
+			return ErrQueueFull
+		case DropOldest:
+			select {
+			case oldest := <-l.queue:
+				l.done(oldest.seq)
+				l.drop(oldest.input, ErrQueueFull)
+			default:
+			}
+		default:
+			select {
+			case l.queue <- event:
+				return nil
+			case <-ctx.Done():
+				l.done(event.seq)
+				return ctx.Err()
+			case <-l.closing:
+				l.done(event.seq)
+				l.drop(input, ErrLoggerClosed)
+				return ErrLoggerClosed
+			}
+		}
+	}
+}
+
+// Flush sends the queued events without waiting for their batches to fill, and waits until
+// the events logged before the call are sent or ctx is done. The events logged during the
+// call are not waited for, so it returns under steady traffic.
+func (l *AsyncLogger) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	if l.flushers == 0 {
+		close(l.flushing)
+	}
+	l.flushers++
+	f := &flush{seq: l.seq, pending: l.pending, done: make(chan struct{})}
+	if f.pending == 0 {
+		close(f.done)
+	} else {
+		l.flushes = append(l.flushes, f)
+	}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		l.removeFlush(f)
+		l.flushers--
+		if l.flushers == 0 {
+			l.flushing = make(chan struct{})
+		}
+		l.mu.Unlock()
+	}()
+
+	select {
+	case <-f.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting events, sends the queued ones and stops the workers. If ctx is done
+// first, the events not sent yet are reported to OnError with the error of ctx.
+func (l *AsyncLogger) Close(ctx context.Context) error {
+	l.closeOnce.Do(func() {
+		close(l.closing)
+		l.closeMu.Lock()
+		close(l.queue)
+		l.closeMu.Unlock()
+	})
+
+	err := l.Flush(ctx)
+	if err != nil {
+		l.cancelSend()
+	}
+	l.wg.Wait()
+	l.cancelSend()
+	return err
+}
+
+// Pending returns the number of events queued or being sent.
+func (l *AsyncLogger) Pending() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pending
+}
+
+func (l *AsyncLogger) work() {
+	defer l.wg.Done()
+	for {
+		event, ok := <-l.queue
+		if !ok {
+			return
+		}
+		l.send(l.collect([]queuedEvent{event}))
+	}
+}
+
+// collect adds the queued events to the batch until it is full, the flush interval elapses
+// or a flush is requested.
+func (l *AsyncLogger) collect(batch []queuedEvent) []queuedEvent {
+	timer := time.NewTimer(l.cfg.FlushInterval)
+	defer timer.Stop()
+	for len(batch) < l.cfg.BatchSize {
+		select {
+		case event, ok := <-l.queue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, event)
+		case <-timer.C:
+			return batch
+		case <-l.flushingChan():
+			// Send what is already queued without waiting.
+			for len(batch) < l.cfg.BatchSize {
+				select {
+				case event, ok := <-l.queue:
+					if !ok {
+						return batch
+					}
+					batch = append(batch, event)
+				default:
+					return batch
+				}
+			}
+		}
+	}
+	return batch
+}
+
+func (l *AsyncLogger) send(batch []queuedEvent) {
+	events := make([]*LogInput, len(batch))
+	for i, event := range batch {
+		events[i] = event.input
+		defer l.done(event.seq)
+	}
+
+	resp, err := l.client.LogBatch(l.sendCtx, &LogBatchInput{Events: events})
+	if err != nil {
+		for _, input := range events {
+			l.fail(input, err)
+		}
+		return
+	}
+	for i, r := range resp.Result.Results {
+		if r.Err != nil {
+			l.fail(events[i], r.Err)
+		}
+	}
+}
+
+func (l *AsyncLogger) flushingChan() chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flushing
+}
+
+// add counts a new pending event and returns its sequence number.
+func (l *AsyncLogger) add() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending++
+	l.seq++
+	return l.seq
+}
+
+// done counts the pending event seq as sent or dropped, for the calls to Flush waiting for it.
+func (l *AsyncLogger) done(seq uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending--
+	for i := 0; i < len(l.flushes); i++ {
+		f := l.flushes[i]
+		if seq > f.seq {
+			continue
+		}
+		f.pending--
+		if f.pending == 0 {
+			close(f.done)
+			l.removeFlush(f)
+			i--
+		}
+	}
+}
+
+// removeFlush removes f from the calls to Flush waiting, l.mu must be held.
+func (l *AsyncLogger) removeFlush(f *flush) {
+	for i, g := range l.flushes {
+		if g == f {
+			l.flushes = append(l.flushes[:i], l.flushes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (l *AsyncLogger) drop(input *LogInput, err error) {
+	if l.cfg.OnDrop != nil {
+		l.cfg.OnDrop(input, err)
+	}
+}
+
+func (l *AsyncLogger) fail(input *LogInput, err error) {
+	if l.cfg.OnError != nil {
+		l.cfg.OnError(input, err)
+	}
+}
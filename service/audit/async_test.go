@@ -0,0 +1,286 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/pangeacyber/go-pangea/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAsyncTestEvent(msg string) *audit.LogInput {
+	return &audit.LogInput{Event: &audit.Event{Message: pangea.String(msg)}}
+}
+
+// blockingBatches makes the LogBatch calls of client wait until release is closed.
+func blockingBatches(client *mocks.AuditClient, release chan struct{}) {
+	client.LogBatchMethod.OnAny().Do(func(ctx context.Context, in *audit.LogBatchInput) (*pangea.PangeaResponse[audit.LogBatchOutput], error) {
+		<-release
+		return mocks.NewResponse(&audit.LogBatchOutput{Results: make([]audit.LogBatchResult, len(in.Events))}), nil
+	})
+}
+
+func TestAsyncLogger_Close_Sends_Queued_Events(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, err := audit.New(srv.Config())
+	assert.NoError(t, err)
+
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		BatchSize:     4,
+		FlushInterval: time.Hour,
+	})
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("async message")))
+	}
+	assert.NoError(t, logger.Close(context.Background()))
+
+	assert.Len(t, srv.Events(), 10)
+	assert.Equal(t, 0, logger.Pending())
+	assert.ErrorIs(t, logger.Log(context.Background(), newAsyncTestEvent("late")), audit.ErrLoggerClosed)
+}
+
+func TestAsyncLogger_Sends_Batches_After_Flush_Interval(t *testing.T) {
+	client := mocks.NewAuditClient()
+	sent := make(chan int, 10)
+	client.LogBatchMethod.OnAny().Do(func(ctx context.Context, in *audit.LogBatchInput) (*pangea.PangeaResponse[audit.LogBatchOutput], error) {
+		sent <- len(in.Events)
+		return mocks.NewResponse(&audit.LogBatchOutput{Results: make([]audit.LogBatchResult, len(in.Events))}), nil
+	})
+
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		Workers:       1,
+		BatchSize:     100,
+		FlushInterval: 50 * time.Millisecond,
+	})
+	defer logger.Close(context.Background())
+
+	assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("first")))
+	assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("second")))
+	select {
+	case n := <-sent:
+		assert.Equal(t, 2, n)
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch not sent after the flush interval")
+	}
+}
+
+func TestAsyncLogger_Flush_Waits_For_Queued_Events(t *testing.T) {
+	client := mocks.NewAuditClient()
+	var mu sync.Mutex
+	var logged int
+	client.LogBatchMethod.OnAny().Do(func(ctx context.Context, in *audit.LogBatchInput) (*pangea.PangeaResponse[audit.LogBatchOutput], error) {
+		mu.Lock()
+		logged += len(in.Events)
+		mu.Unlock()
+		return mocks.NewResponse(&audit.LogBatchOutput{Results: make([]audit.LogBatchResult, len(in.Events))}), nil
+	})
+
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	})
+	defer logger.Close(context.Background())
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("message")))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, logger.Flush(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 5, logged)
+	assert.Equal(t, 0, logger.Pending())
+}
+
+func TestAsyncLogger_Flush_Does_Not_Wait_For_Events_Logged_After_It(t *testing.T) {
+	client := mocks.NewAuditClient()
+	release := make(chan struct{})
+	var logger *audit.AsyncLogger
+	client.LogBatchMethod.OnAny().Do(func(ctx context.Context, in *audit.LogBatchInput) (*pangea.PangeaResponse[audit.LogBatchOutput], error) {
+		if pangea.StringValue(in.Events[0].Event.Message) == "before" {
+			// The batch is sent by the flush, the traffic goes on.
+			for i := 0; i < 3; i++ {
+				assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("after")))
+			}
+		} else {
+			<-release
+		}
+		return mocks.NewResponse(&audit.LogBatchOutput{Results: make([]audit.LogBatchResult, len(in.Events))}), nil
+	})
+
+	logger = audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		Workers:       1,
+		BatchSize:     100,
+		FlushInterval: time.Hour,
+	})
+	defer logger.Close(context.Background())
+	defer close(release)
+
+	assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("before")))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, logger.Flush(ctx))
+	assert.Equal(t, 3, logger.Pending())
+}
+
+func TestAsyncLogger_Reports_Failed_Events(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.LogBatchMethod.ReturnError(pangea.ErrRateLimited)
+
+	var mu sync.Mutex
+	var failed []*audit.LogInput
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		OnError: func(input *audit.LogInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.ErrorIs(t, err, pangea.ErrRateLimited)
+			failed = append(failed, input)
+		},
+	})
+	event := newAsyncTestEvent("message")
+	assert.NoError(t, logger.Log(context.Background(), event))
+	assert.NoError(t, logger.Close(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []*audit.LogInput{event}, failed)
+}
+
+func TestAsyncLogger_Reports_Events_Failed_In_Batch(t *testing.T) {
+	client := mocks.NewAuditClient()
+	failure := errors.New("event rejected")
+	client.LogBatchMethod.OnAny().Do(func(ctx context.Context, in *audit.LogBatchInput) (*pangea.PangeaResponse[audit.LogBatchOutput], error) {
+		out := &audit.LogBatchOutput{Results: make([]audit.LogBatchResult, len(in.Events))}
+		for i, e := range in.Events {
+			if pangea.StringValue(e.Event.Message) == "bad" {
+				out.Results[i].Err = failure
+			}
+		}
+		return mocks.NewResponse(out), nil
+	})
+
+	var mu sync.Mutex
+	var failed []string
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		Workers: 1,
+		OnError: func(input *audit.LogInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.ErrorIs(t, err, failure)
+			failed = append(failed, pangea.StringValue(input.Event.Message))
+		},
+	})
+	for _, msg := range []string{"good", "bad", "good"} {
+		assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent(msg)))
+	}
+	assert.NoError(t, logger.Close(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"bad"}, failed)
+}
+
+func TestAsyncLogger_Backpressure(t *testing.T) {
+	tests := []struct {
+		policy  audit.BackpressurePolicy
+		logErr  error
+		dropped string
+	}{
+		{policy: audit.DropNewest, logErr: audit.ErrQueueFull, dropped: "third"},
+		{policy: audit.DropOldest, dropped: "second"},
+	}
+	for _, tt := range tests {
+		client := mocks.NewAuditClient()
+		release := make(chan struct{})
+		blockingBatches(client, release)
+
+		var dropped []string
+		logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+			QueueSize:    1,
+			Workers:      1,
+			BatchSize:    1,
+			Backpressure: tt.policy,
+			OnDrop: func(input *audit.LogInput, err error) {
+				assert.ErrorIs(t, err, audit.ErrQueueFull)
+				dropped = append(dropped, pangea.StringValue(input.Event.Message))
+			},
+		})
+
+		// The worker takes the first event and blocks, the second fills the queue.
+		assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("first")))
+		assert.Eventually(t, func() bool { return len(client.LogBatchMethod.Calls()) == 1 }, 5*time.Second, time.Millisecond)
+		assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("second")))
+
+		err := logger.Log(context.Background(), newAsyncTestEvent("third"))
+		if tt.logErr != nil {
+			assert.ErrorIs(t, err, tt.logErr)
+		} else {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, []string{tt.dropped}, dropped)
+		assert.Equal(t, 2, logger.Pending())
+
+		close(release)
+		assert.NoError(t, logger.Close(context.Background()))
+	}
+}
+
+func TestAsyncLogger_Block_Waits_Until_Context_Is_Done(t *testing.T) {
+	client := mocks.NewAuditClient()
+	release := make(chan struct{})
+	blockingBatches(client, release)
+
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		QueueSize: 1,
+		Workers:   1,
+		BatchSize: 1,
+	})
+	assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("first")))
+	assert.Eventually(t, func() bool { return len(client.LogBatchMethod.Calls()) == 1 }, 5*time.Second, time.Millisecond)
+	assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("second")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, logger.Log(ctx, newAsyncTestEvent("third")), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, logger.Close(context.Background()))
+	assert.Len(t, client.LogBatchMethod.Calls(), 2)
+}
+
+func TestAsyncLogger_Close_Returns_When_Context_Is_Done(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.LogBatchMethod.OnAny().Do(func(ctx context.Context, in *audit.LogBatchInput) (*pangea.PangeaResponse[audit.LogBatchOutput], error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	var mu sync.Mutex
+	var failed int
+	logger := audit.NewAsyncLogger(client, &audit.AsyncLoggerConfig{
+		OnError: func(input *audit.LogInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.ErrorIs(t, err, context.Canceled)
+			failed++
+		},
+	})
+	assert.NoError(t, logger.Log(context.Background(), newAsyncTestEvent("message")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, logger.Close(ctx), context.DeadlineExceeded)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, failed)
+}
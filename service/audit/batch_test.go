@@ -0,0 +1,94 @@
+package audit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogBatch_Returns_Result_Of_Each_Event(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, err := audit.New(srv.Config(), audit.WithLogSigningEnabled("./testdata/privkey"))
+	assert.NoError(t, err)
+
+	input := &audit.LogBatchInput{Concurrency: 2}
+	for i := 0; i < 10; i++ {
+		input.Events = append(input.Events, &audit.LogInput{
+			Event:      &audit.Event{Message: pangea.String("batched message")},
+			ReturnHash: pangea.Bool(true),
+		})
+	}
+	// The event without message is rejected by the service.
+	input.Events[3].Event.Message = nil
+	input.Events[7] = nil
+
+	resp, err := client.LogBatch(context.Background(), input)
+	assert.NoError(t, err)
+	out := resp.Result
+	assert.Len(t, out.Results, 10)
+	assert.Equal(t, 2, out.Failed())
+
+	var validationErr *pangea.ValidationError
+	assert.ErrorAs(t, out.Results[3].Err, &validationErr)
+	assert.ErrorContains(t, out.Results[7].Err, "nil event")
+	assert.ErrorContains(t, out.Err(), "event 3")
+	assert.ErrorIs(t, out.Err(), pangea.ErrValidation)
+	for i, r := range out.Results {
+		if i == 3 || i == 7 {
+			assert.Nil(t, r.Output)
+			continue
+		}
+		assert.NoError(t, r.Err)
+		assert.NotEmpty(t, pangea.StringValue(r.Output.Hash))
+		assert.NotEmpty(t, r.Response.IdempotencyKey)
+	}
+
+	events := srv.Events()
+	assert.Len(t, events, 8)
+	for _, e := range events {
+		assert.True(t, e.VerifySignature())
+		assert.NotNil(t, e.Signature)
+	}
+}
+
+func TestLogBatch_When_Context_Is_Canceled_It_Returns_Context_Error(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, _ := audit.New(srv.Config())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp, err := client.LogBatch(ctx, &audit.LogBatchInput{
+		Events: []*audit.LogInput{{Event: &audit.Event{Message: pangea.String("message")}}},
+	})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, resp.Result.Err(), context.Canceled)
+	assert.Empty(t, srv.Events())
+}
+
+func TestLogBatch_Derives_Idempotency_Key_Of_Each_Event_From_Context(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, _ := audit.New(srv.Config())
+
+	input := &audit.LogBatchInput{}
+	for i := 0; i < 3; i++ {
+		input.Events = append(input.Events, &audit.LogInput{Event: &audit.Event{Message: pangea.String("batched message")}})
+	}
+	ctx := pangea.WithIdempotencyKey(context.Background(), "batch-key")
+	for attempt := 0; attempt < 2; attempt++ {
+		// The batch can be logged again with the same keys.
+		resp, err := client.LogBatch(ctx, input)
+		assert.NoError(t, err)
+		assert.NoError(t, resp.Result.Err())
+		for i, r := range resp.Result.Results {
+			assert.Equal(t, fmt.Sprintf("batch-key/%v", i), r.Response.IdempotencyKey)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+// defaultLogTimeout is the default MiddlewareOptions.LogTimeout.
+const defaultLogTimeout = 5 * time.Second
+
+// MiddlewareOptions configures the middleware returned by Middleware.
+type MiddlewareOptions struct {
+	// Identity returns the actor of a request, e.g. the user of its session. Defaults to the
+	// user of the basic authentication of the request, the events have no actor if empty.
+	Identity func(r *http.Request) string
+
+	// Route returns the route of a request, e.g. "/users/{id}" from the router. Defaults to
+	// the path of the request. It is called again once the request is handled, as the routers
+	// set the route of the requests while handling them, e.g. r.Pattern with http.ServeMux.
+	// It falls back to the route returned before the request was handled if it is empty.
+	Route func(r *http.Request) string
+
+	// Target returns the target of a request. Defaults to the path of the request.
+	Target func(r *http.Request) string
+
+	// The patterns of the routes audited, all if empty, and of the routes not audited.
+	// The patterns have the syntax of path.Match, e.g. "/users/*". They are matched before
+	// the request is handled, so against the route returned by Route then, e.g. the path of
+	// the request if the middleware wraps the router.
+	AllowRoutes []string
+	DenyRoutes  []string
+
+	// The header set by a trusted proxy with the IP of the client, e.g. "X-Forwarded-For".
+	// Defaults to the remote address of the requests.
+	ClientIPHeader string
+
+	// The number of trusted proxies appending the address of their client to ClientIPHeader,
+	// defaults to 1. The client IP is the entry added by the first trusted proxy, counted from
+	// the right, as the entries on its left can be set by the client.
+	TrustedHops int
+
+	// Set to log the query string of the requests in the message of the events. It is not
+	// logged by default, as it may contain secrets, e.g. tokens.
+	LogQuery bool
+
+	// The maximum time to wait for logger to accept the event of a request, e.g. for room in
+	// the queue of an AsyncLogger with the Block policy while Pangea is down. Defaults to 5s,
+	// the events not accepted in time are reported to OnError.
+	LogTimeout time.Duration
+
+	// OnError is called with the requests failing to be logged and the error
+	OnError func(r *http.Request, err error)
+}
+
+// Middleware returns an HTTP middleware logging every request with logger, e.g. an AsyncLogger
+// so the responses don't wait for Pangea:
+//
+//	logger := audit.NewAsyncLogger(auditcli, nil)
+//	defer logger.Close(context.Background())
+//
+//	handler := audit.Middleware(logger, &audit.MiddlewareOptions{
+//		Identity:   func(r *http.Request) string { return r.Header.Get("X-User") },
+//		DenyRoutes: []string{"/healthz"},
+//	})(mux)
+//
+// The events have the actor of the request, the method and route as action, the target, the
+// IP of the client as source and the status code of the response as status. Their message
+// also has the path of the request, with its query string if LogQuery is set, and its latency.
+func Middleware(logger EventLogger, opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	var o MiddlewareOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Identity == nil {
+		o.Identity = basicAuthUser
+	}
+	if o.Route == nil {
+		o.Route = requestPath
+	}
+	if o.Target == nil {
+		o.Target = requestPath
+	}
+	if o.TrustedHops <= 0 {
+		o.TrustedHops = 1
+	}
+	if o.LogTimeout <= 0 {
+		o.LogTimeout = defaultLogTimeout
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := o.Route(r)
+			if !o.audited(route) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			defer func() {
+				// A panicking handler is logged as failed, the panic goes on.
+				if p := recover(); p != nil {
+					sw.status = http.StatusInternalServerError
+					defer panic(p)
+				}
+				latency := time.Since(start)
+				if handled := o.Route(r); handled != "" {
+					route = handled
+				}
+				input := o.newLogInput(r, route, sw.statusCode(), latency)
+				// The request is logged even if the client went away, to audit it anyway.
+				ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), o.LogTimeout)
+				defer cancel()
+				if err := logger.Log(ctx, input); err != nil && o.OnError != nil {
+					o.OnError(r, err)
+				}
+			}()
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+func (o *MiddlewareOptions) audited(route string) bool {
+	if matchRoute(o.DenyRoutes, route) {
+		return false
+	}
+	return len(o.AllowRoutes) == 0 || matchRoute(o.AllowRoutes, route)
+}
+
+func (o *MiddlewareOptions) newLogInput(r *http.Request, route string, status int, latency time.Duration) *LogInput {
+	action := r.Method + " " + route
+	uri := r.URL.Path
+	if o.LogQuery {
+		uri = r.URL.RequestURI()
+	}
+	event := &Event{
+		Action:  pangea.String(action),
+		Status:  pangea.String(strconv.Itoa(status)),
+		Message: pangea.String(fmt.Sprintf("%v %v %v in %v", r.Method, uri, status, latency)),
+	}
+	if actor := o.Identity(r); actor != "" {
+		event.Actor = pangea.String(actor)
+	}
+	if target := o.Target(r); target != "" {
+		event.Target = pangea.String(target)
+	}
+	if ip := o.clientIP(r); ip != "" {
+		event.Source = pangea.String(ip)
+	}
+	return &LogInput{Event: event}
+}
+
+// clientIP returns the IP of the client of the request.
+func (o *MiddlewareOptions) clientIP(r *http.Request) string {
+	if o.ClientIPHeader != "" {
+		// Each proxy appends the address of its client, skip the trusted ones from the right.
+		if v := r.Header.Values(o.ClientIPHeader); len(v) > 0 {
+			ips := strings.Split(strings.Join(v, ","), ",")
+			i := len(ips) - o.TrustedHops
+			if i < 0 {
+				i = 0
+			}
+			return strings.TrimSpace(ips[i])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func matchRoute(patterns []string, route string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, route); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func basicAuthUser(r *http.Request) string {
+	user, _, _ := r.BasicAuth()
+	return user
+}
+
+func requestPath(r *http.Request) string {
+	return r.URL.Path
+}
+
+// statusWriter records the status code of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	// The informational responses are followed by the final one.
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection of the wrapped writer, e.g. for a WebSocket upgrade. The
+// response is logged as switching protocols.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("audit: %T does not support hijacking", w.ResponseWriter)
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// statusCode returns the status code of the response, 200 if the handler wrote nothing.
+func (w *statusWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,284 @@
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger records the events logged, or fails with err.
+type recordingLogger struct {
+	mu     sync.Mutex
+	events []*audit.Event
+	err    error
+}
+
+func (l *recordingLogger) Log(ctx context.Context, input *audit.LogInput) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+	l.events = append(l.events, input.Event)
+	return nil
+}
+
+func (l *recordingLogger) Events() []*audit.Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]*audit.Event(nil), l.events...)
+}
+
+func teapot(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusTeapot)
+}
+
+func TestMiddleware_Logs_Requests(t *testing.T) {
+	logger := &recordingLogger{}
+	handler := audit.Middleware(logger, nil)(http.HandlerFunc(teapot))
+
+	req := httptest.NewRequest("POST", "/users/42?dry_run=true", nil)
+	req.RemoteAddr = "10.0.0.1:5555"
+	req.SetBasicAuth("alice", "secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	events := logger.Events()
+	assert.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, "alice", pangea.StringValue(e.Actor))
+	assert.Equal(t, "POST /users/42", pangea.StringValue(e.Action))
+	assert.Equal(t, "/users/42", pangea.StringValue(e.Target))
+	assert.Equal(t, "10.0.0.1", pangea.StringValue(e.Source))
+	assert.Equal(t, "418", pangea.StringValue(e.Status))
+	assert.True(t, strings.HasPrefix(pangea.StringValue(e.Message), "POST /users/42 418 in "))
+}
+
+func TestMiddleware_Logs_Query_If_LogQuery_Is_Set(t *testing.T) {
+	logger := &recordingLogger{}
+	handler := audit.Middleware(logger, &audit.MiddlewareOptions{LogQuery: true})(http.HandlerFunc(teapot))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42?dry_run=true", nil))
+	events := logger.Events()
+	assert.Len(t, events, 1)
+	assert.True(t, strings.HasPrefix(pangea.StringValue(events[0].Message), "GET /users/42?dry_run=true 418 in "))
+}
+
+func TestMiddleware_Uses_Options(t *testing.T) {
+	logger := &recordingLogger{}
+	handler := audit.Middleware(logger, &audit.MiddlewareOptions{
+		Identity:       func(r *http.Request) string { return r.Header.Get("X-User") },
+		Route:          func(r *http.Request) string { return "/users/{id}" },
+		Target:         func(r *http.Request) string { return "user " + strings.TrimPrefix(r.URL.Path, "/users/") },
+		ClientIPHeader: "X-Forwarded-For",
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	req := httptest.NewRequest("DELETE", "/users/42", nil)
+	req.Header.Set("X-User", "bob")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	events := logger.Events()
+	assert.Len(t, events, 1)
+	e := events[0]
+	assert.Equal(t, "bob", pangea.StringValue(e.Actor))
+	assert.Equal(t, "DELETE /users/{id}", pangea.StringValue(e.Action))
+	assert.Equal(t, "user 42", pangea.StringValue(e.Target))
+	assert.Equal(t, "10.0.0.1", pangea.StringValue(e.Source))
+	assert.Equal(t, "200", pangea.StringValue(e.Status))
+}
+
+func TestMiddleware_Skips_Trusted_Hops_Of_Client_IP_Header(t *testing.T) {
+	for _, tt := range []struct {
+		hops    int
+		headers []string
+		want    string
+	}{
+		// The entries on the left of the trusted proxies can be spoofed by the client.
+		{0, []string{"198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{2, []string{"198.51.100.1, 203.0.113.7, 10.0.0.1"}, "203.0.113.7"},
+		{2, []string{"198.51.100.1, 203.0.113.7", "10.0.0.1"}, "203.0.113.7"},
+		{3, []string{"203.0.113.7"}, "203.0.113.7"},
+	} {
+		logger := &recordingLogger{}
+		handler := audit.Middleware(logger, &audit.MiddlewareOptions{
+			ClientIPHeader: "X-Forwarded-For",
+			TrustedHops:    tt.hops,
+		})(http.HandlerFunc(teapot))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, h := range tt.headers {
+			req.Header.Add("X-Forwarded-For", h)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, tt.want, pangea.StringValue(logger.Events()[0].Source))
+	}
+}
+
+// ctxCheckingLogger fails with the error of the context of the events, like a logger waiting
+// for room in its queue.
+type ctxCheckingLogger struct {
+	recordingLogger
+}
+
+func (l *ctxCheckingLogger) Log(ctx context.Context, input *audit.LogInput) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.recordingLogger.Log(ctx, input)
+}
+
+func TestMiddleware_Logs_Requests_Of_Disconnected_Clients(t *testing.T) {
+	logger := &ctxCheckingLogger{}
+	handler := audit.Middleware(logger, nil)(http.HandlerFunc(teapot))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assert.Len(t, logger.Events(), 1)
+}
+
+type routeKey struct{}
+
+func TestMiddleware_Uses_Route_Set_By_Router(t *testing.T) {
+	// The router sets the route of the request while handling it.
+	router := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*r.Context().Value(routeKey{}).(*string) = "/users/{id}"
+		teapot(w, r)
+	})
+	logger := &recordingLogger{}
+	handler := audit.Middleware(logger, &audit.MiddlewareOptions{
+		Route: func(r *http.Request) string { return *r.Context().Value(routeKey{}).(*string) },
+	})(router)
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	var route string
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), routeKey{}, &route)))
+	events := logger.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "GET /users/{id}", pangea.StringValue(events[0].Action))
+}
+
+func TestMiddleware_Supports_Hijacking(t *testing.T) {
+	logger := &recordingLogger{}
+	srv := httptest.NewServer(audit.Middleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+		rw.Flush()
+	})))
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "test")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	// The hijacked connection is not waited for by the server, the handler logs it on return.
+	assert.Eventually(t, func() bool { return len(logger.Events()) == 1 }, 5*time.Second, time.Millisecond)
+	events := logger.Events()
+	assert.Equal(t, "101", pangea.StringValue(events[0].Status))
+}
+
+// blockingLogger waits for room in a full queue until the context of the events is done.
+type blockingLogger struct{}
+
+func (blockingLogger) Log(ctx context.Context, input *audit.LogInput) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestMiddleware_Stops_Waiting_For_Logger_After_LogTimeout(t *testing.T) {
+	var reported error
+	handler := audit.Middleware(blockingLogger{}, &audit.MiddlewareOptions{
+		LogTimeout: 10 * time.Millisecond,
+		OnError:    func(r *http.Request, err error) { reported = err },
+	})(http.HandlerFunc(teapot))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assert.ErrorIs(t, reported, context.DeadlineExceeded)
+}
+
+func TestMiddleware_Filters_Routes(t *testing.T) {
+	logger := &recordingLogger{}
+	handler := audit.Middleware(logger, &audit.MiddlewareOptions{
+		AllowRoutes: []string{"/api/*", "/admin"},
+		DenyRoutes:  []string{"/api/health"},
+	})(http.HandlerFunc(teapot))
+
+	for _, p := range []string{"/api/users", "/api/health", "/admin", "/static/app.js", "/api/users/42"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", p, nil))
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+	}
+
+	var actions []string
+	for _, e := range logger.Events() {
+		actions = append(actions, pangea.StringValue(e.Action))
+	}
+	assert.Equal(t, []string{"GET /api/users", "GET /admin"}, actions)
+}
+
+func TestMiddleware_Logs_Panicking_Handler(t *testing.T) {
+	logger := &recordingLogger{}
+	handler := audit.Middleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	assert.PanicsWithValue(t, "boom", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	})
+	events := logger.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "500", pangea.StringValue(events[0].Status))
+}
+
+func TestMiddleware_Reports_Errors(t *testing.T) {
+	failure := errors.New("queue full")
+	var reported error
+	handler := audit.Middleware(&recordingLogger{err: failure}, &audit.MiddlewareOptions{
+		OnError: func(r *http.Request, err error) { reported = err },
+	})(http.HandlerFunc(teapot))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.ErrorIs(t, reported, failure)
+}
+
+func TestMiddleware_Sends_Events_With_AsyncLogger(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, err := audit.New(srv.Config())
+	assert.NoError(t, err)
+	logger := audit.NewAsyncLogger(client, nil)
+
+	handler := audit.Middleware(logger, nil)(http.HandlerFunc(teapot))
+	for i := 0; i < 3; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	assert.NoError(t, logger.Close(context.Background()))
+
+	events := srv.Events()
+	assert.Len(t, events, 3)
+	assert.Equal(t, "GET /", pangea.StringValue(events[0].Event.Action))
+}
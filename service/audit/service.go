@@ -10,6 +10,7 @@ import (
 
 type Client interface {
 	Log(context.Context, *LogInput) (*pangea.PangeaResponse[LogOutput], error)
+	LogBatch(context.Context, *LogBatchInput) (*pangea.PangeaResponse[LogBatchOutput], error)
 	Search(context.Context, *SearchInput) (*pangea.PangeaResponse[SearchOutput], error)
 	SearchResults(context.Context, *SearchResultInput) (*pangea.PangeaResponse[SearchResultOutput], error)
 	Root(context.Context, *RootInput) (*pangea.PangeaResponse[RootOutput], error)
@@ -29,6 +30,12 @@ func New(cfg *pangea.Config, opts ...Option) (*Audit, error) {
 	cli := &Audit{
 		Client: pangea.NewClient("audit", cfg),
 	}
+	if err := cli.Client.Err(); err != nil {
+		return nil, err
+	}
+	// Logging twice the same event creates two entries, so logs are only retried with an idempotency key,
+	// generated for each log unless set with pangea.WithIdempotencyKey.
+	cli.Client.NonIdempotentPaths = []string{"v1/log"}
 	for _, opt := range opts {
 		err := opt(cli)
 		if err != nil {
@@ -0,0 +1,227 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/internal/signer"
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+// EventLogger logs audit events without returning the response of Pangea, e.g. in the background.
+// It is implemented by AsyncLogger and Spool.
+type EventLogger interface {
+	Log(ctx context.Context, input *LogInput) error
+}
+
+var (
+	_ EventLogger = (*AsyncLogger)(nil)
+	_ EventLogger = (*Spool)(nil)
+)
+
+// SlogOptions configures a SlogHandler.
+type SlogOptions struct {
+	// The minimum level of the records logged, defaults to slog.LevelInfo
+	Level slog.Leveler
+
+	// The keys of the attributes set as the fields of the events, defaults to "actor", "action",
+	// "target", "status" and "source". The keys of the attributes of a group are prefixed with
+	// the group and a dot, e.g. "user.id".
+	ActorKey  string
+	ActionKey string
+	TargetKey string
+	StatusKey string
+	SourceKey string
+
+	// Signs the events if set, e.g. with the Signer of an Audit client
+	Signer signer.Signer
+
+	// Logs the events if set instead of the client, e.g. an AsyncLogger so the records
+	// are not logged while the caller waits
+	Logger EventLogger
+}
+
+// SlogHandler is a slog.Handler logging the records to the Secure Audit Log:
+//
+//	logger := slog.New(audit.NewSlogHandler(auditcli, nil))
+//	logger.Info("user logged in", "actor", "alice", "action", "login", "ip", "10.0.0.1")
+//
+// The attributes with the keys of the options are set as the fields of the events, the message,
+// level and other attributes of the records are encoded as a JSON object in the message of
+// the events, e.g. {"level":"INFO","msg":"user logged in","ip":"10.0.0.1"}.
+type SlogHandler struct {
+	client Client
+	opts   SlogOptions
+	fields map[string]func(*Event) **string
+
+	attrs  []slog.Attr // attributes added with WithAttrs, in their groups
+	groups []string    // groups opened with WithGroup
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// NewSlogHandler returns a SlogHandler logging the records with client, or opts.Logger if set.
+func NewSlogHandler(client Client, opts *SlogOptions) *SlogHandler {
+	h := &SlogHandler{client: client}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	h.fields = map[string]func(*Event) **string{
+		keyOr(h.opts.ActorKey, "actor"):   func(e *Event) **string { return &e.Actor },
+		keyOr(h.opts.ActionKey, "action"): func(e *Event) **string { return &e.Action },
+		keyOr(h.opts.TargetKey, "target"): func(e *Event) **string { return &e.Target },
+		keyOr(h.opts.StatusKey, "status"): func(e *Event) **string { return &e.Status },
+		keyOr(h.opts.SourceKey, "source"): func(e *Event) **string { return &e.Source },
+	}
+	return h
+}
+
+func keyOr(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return key
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle logs the record and returns the error of Pangea, if any.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	event := &Event{}
+	if !r.Time.IsZero() {
+		event.Timestamp = pangea.String(r.Time.UTC().Format(time.RFC3339Nano))
+	}
+
+	message := map[string]any{
+		slog.LevelKey:   r.Level.String(),
+		slog.MessageKey: r.Message,
+	}
+	for _, a := range h.attrs {
+		h.addAttr(event, message, a.Key, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		a = nestAttr(h.groups, a)
+		h.addAttr(event, message, a.Key, a)
+		return true
+	})
+
+	b, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("audit: slog: %w", err)
+	}
+	event.Message = pangea.String(string(b))
+
+	input := &LogInput{Event: event}
+	if h.opts.Signer != nil {
+		if err := input.Sign(h.opts.Signer); err != nil {
+			return err
+		}
+	}
+	if h.opts.Logger != nil {
+		return h.opts.Logger.Log(ctx, input)
+	}
+	_, err = h.client.Log(ctx, input)
+	return err
+}
+
+// addAttr sets the field of event mapped to key, or adds the attribute to message.
+func (h *SlogHandler) addAttr(event *Event, message map[string]any, key string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		// The attributes of a group without key are inlined.
+		group := message
+		if a.Key != "" {
+			group = subgroup(message, a.Key)
+		}
+		for _, ga := range attrs {
+			h.addAttr(event, group, joinKey(key, ga.Key), ga)
+		}
+		return
+	}
+	if field, ok := h.fields[key]; ok {
+		*field(event) = pangea.String(a.Value.String())
+		return
+	}
+	message[a.Key] = slogValue(a.Value)
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, nestAttr(h.groups, a))
+	}
+	return &h2
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	return &h2
+}
+
+// nestAttr returns the attribute a in the groups, e.g. {"g1": {"g2": {a}}}.
+func nestAttr(groups []string, a slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+	return a
+}
+
+func subgroup(message map[string]any, key string) map[string]any {
+	if group, ok := message[key].(map[string]any); ok {
+		return group
+	}
+	group := map[string]any{}
+	message[key] = group
+	return group
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	if key == "" {
+		return prefix
+	}
+	return prefix + "." + key
+}
+
+// slogValue returns the value of an attribute to encode in JSON.
+func slogValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindAny:
+		switch a := v.Any().(type) {
+		case error:
+			return a.Error()
+		case json.Marshaler:
+			return a
+		case fmt.Stringer:
+			return a.String()
+		}
+	}
+	return v.Any()
+}
@@ -0,0 +1,92 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/pangeacyber/go-pangea/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.LogMethod.Return(&audit.LogOutput{})
+	h := audit.NewSlogHandler(client, nil)
+
+	results := func() []map[string]any {
+		var ms []map[string]any
+		for _, call := range client.LogMethod.Calls() {
+			var m map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(pangea.StringValue(call.Input.Event.Message)), &m))
+			if ts := call.Input.Event.Timestamp; ts != nil {
+				m[slog.TimeKey] = *ts
+			}
+			ms = append(ms, m)
+		}
+		return ms
+	}
+	assert.NoError(t, slogtest.TestHandler(h, results))
+}
+
+func TestSlogHandler_Maps_Attributes_To_Event_Fields(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.LogMethod.Return(&audit.LogOutput{})
+	logger := slog.New(audit.NewSlogHandler(client, &audit.SlogOptions{
+		Level:    slog.LevelWarn,
+		ActorKey: "user.id",
+	}))
+
+	logger.Info("not logged")
+	logger.With("source", "api").WithGroup("user").Warn("password changed",
+		"id", "alice",
+		"ip", "10.0.0.1",
+		slog.String("action", "grouped, not mapped"),
+		slog.Group("", "status", "success"),
+	)
+	logger.Error("failed", "target", "db", "err", errors.New("disk full"), "took", time.Second)
+
+	calls := client.LogMethod.Calls()
+	assert.Len(t, calls, 2)
+
+	event := calls[0].Input.Event
+	assert.Equal(t, "alice", pangea.StringValue(event.Actor))
+	assert.Equal(t, "api", pangea.StringValue(event.Source))
+	assert.Nil(t, event.Action)
+	assert.Nil(t, event.Status)
+	assert.NotNil(t, event.Timestamp)
+	assert.JSONEq(t, `{
+		"level": "WARN",
+		"msg": "password changed",
+		"user": {"ip": "10.0.0.1", "action": "grouped, not mapped", "status": "success"}
+	}`, pangea.StringValue(event.Message))
+
+	event = calls[1].Input.Event
+	assert.Equal(t, "db", pangea.StringValue(event.Target))
+	assert.JSONEq(t, `{"level": "ERROR", "msg": "failed", "err": "disk full", "took": "1s"}`, pangea.StringValue(event.Message))
+}
+
+func TestSlogHandler_Signs_And_Sends_Events(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, err := audit.New(srv.Config(), audit.WithLogSigningEnabled("./testdata/privkey"))
+	assert.NoError(t, err)
+	logger := audit.NewAsyncLogger(client, nil)
+
+	h := audit.NewSlogHandler(client, &audit.SlogOptions{Signer: client.Signer, Logger: logger})
+	slog.New(h).Info("user logged in", "actor", "alice", "action", "login")
+	assert.NoError(t, logger.Close(context.Background()))
+
+	events := srv.Events()
+	assert.Len(t, events, 1)
+	assert.True(t, events[0].VerifySignature())
+	assert.Equal(t, "alice", pangea.StringValue(events[0].Event.Actor))
+	assert.Equal(t, "login", pangea.StringValue(events[0].Event.Action))
+}
@@ -0,0 +1,619 @@
+package audit
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+var (
+	// ErrSpoolClosed is returned by the Log calls after a Spool is closed.
+	ErrSpoolClosed = errors.New("audit: spool closed")
+
+	// ErrSpoolCorrupted is the error of the records of a Spool failing their checksum.
+	ErrSpoolCorrupted = errors.New("audit: spool corrupted")
+)
+
+const (
+	spoolSegmentExt  = ".seg"
+	spoolCursorFile  = "cursor"
+	spoolHeaderSize  = 8 // length and checksum of a record
+	spoolMaxRecord   = 16 << 20
+	spoolSegmentName = "%016d" + spoolSegmentExt
+)
+
+var spoolCRCTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SpoolConfig configures a Spool, the zero values are replaced by the defaults.
+type SpoolConfig struct {
+	SegmentSize   int64         // Size after which a new segment file is started, defaults to 16MiB
+	RetryInterval time.Duration // Delay before sending an event again after a failure, defaults to 5s
+
+	// Don't sync the segment files after each event. The events logged since the last sync
+	// may be lost if the machine crashes, but not if the process does.
+	NoSync bool
+
+	// OnError is called with the events failing with a permanent error, which are removed
+	// from the spool, e.g. rejected by Pangea or failing to be signed. The events failing
+	// because Pangea is unreachable, throttles the requests, fails with a 5xx status or
+	// rejects the token, e.g. while it is renewed, are sent again. The records of the segment
+	// files found corrupted when the spool is opened are skipped and reported with a nil input
+	// and ErrSpoolCorrupted.
+	OnError func(input *LogInput, err error)
+}
+
+var defaultSpoolConfig = SpoolConfig{
+	SegmentSize:   16 << 20,
+	RetryInterval: 5 * time.Second,
+}
+
+// SpoolStats describes the backlog of a Spool.
+type SpoolStats struct {
+	Events   int           // Number of events not sent yet
+	Bytes    int64         // Size of the events not sent yet in the segment files
+	Segments int           // Number of segment files
+	Age      time.Duration // Time since the oldest event not sent yet was logged, 0 if none
+}
+
+// Spool is a write-ahead log of audit events, so they are not lost when Pangea can't be reached.
+// The events are appended to segment files in dir and sent in order by a background goroutine,
+// retrying until they are accepted or rejected by Pangea. The events not sent yet when the process
+// stops are sent once the spool is opened again.
+//
+// Each event is sent with an idempotency key stored with it, so an event sent again after a
+// restart is only logged once.
+type Spool struct {
+	client Client
+	dir    string
+	cfg    SpoolConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	closed   bool
+	writer   *os.File
+	writeSeg int64
+	writeOff int64
+	backlog  []spoolEntry
+	bytes    int64
+	segments []int64       // segment files, in order
+	wake     chan struct{} // signaled when an event is appended
+	idle     chan struct{} // closed when the backlog is empty
+
+	reader    *os.File
+	readerSeg int64
+}
+
+// spoolEntry locates an event not sent yet.
+type spoolEntry struct {
+	seg  int64
+	off  int64
+	size int64
+	time time.Time
+}
+
+// spoolRecord is the payload of a record of a segment file.
+type spoolRecord struct {
+	Time           time.Time `json:"time"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	Input          *LogInput `json:"input"`
+}
+
+// OpenSpool opens the spool in dir, creating it if needed, and starts sending its events
+// with client. A record partially written when the process stopped is discarded.
+func OpenSpool(dir string, client Client, cfg *SpoolConfig) (*Spool, error) {
+	c := defaultSpoolConfig
+	if cfg != nil {
+		c.NoSync = cfg.NoSync
+		c.OnError = cfg.OnError
+		if cfg.SegmentSize > 0 {
+			c.SegmentSize = cfg.SegmentSize
+		}
+		if cfg.RetryInterval > 0 {
+			c.RetryInterval = cfg.RetryInterval
+		}
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("audit: spool: %w", err)
+	}
+
+	s := &Spool{
+		client:    client,
+		dir:       dir,
+		cfg:       c,
+		done:      make(chan struct{}),
+		wake:      make(chan struct{}, 1),
+		idle:      make(chan struct{}),
+		readerSeg: -1,
+	}
+	if err := s.load(); err != nil {
+		s.closeFiles()
+		return nil, err
+	}
+	if len(s.backlog) == 0 {
+		close(s.idle)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.replay()
+	return s, nil
+}
+
+// Log appends the event to the spool, it is sent in the background. The event is durable
+// once Log returns, unless NoSync is set.
+func (s *Spool) Log(ctx context.Context, input *LogInput) error {
+	if input == nil || input.Event == nil {
+		return errors.New("audit: nil event")
+	}
+	key, err := pangea.NewIdempotencyKey()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(spoolRecord{Time: now, IdempotencyKey: key, Input: input})
+	if err != nil {
+		return fmt.Errorf("audit: spool: %w", err)
+	}
+	if len(payload) > spoolMaxRecord {
+		return fmt.Errorf("audit: spool: event of %v bytes is too large", len(payload))
+	}
+	record := make([]byte, spoolHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record, uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:], crc32.Checksum(payload, spoolCRCTable))
+	copy(record[spoolHeaderSize:], payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrSpoolClosed
+	}
+	if s.writer == nil || s.writeOff >= s.cfg.SegmentSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.writer.Write(record)
+	if err == nil && !s.cfg.NoSync {
+		err = s.writer.Sync()
+	}
+	if err != nil {
+		// Start a new segment, so the next records don't follow a partial one.
+		s.writeOff += int64(n)
+		s.writer.Close()
+		s.writer = nil
+		return fmt.Errorf("audit: spool: %w", err)
+	}
+
+	if len(s.backlog) == 0 {
+		s.idle = make(chan struct{})
+	}
+	s.backlog = append(s.backlog, spoolEntry{seg: s.writeSeg, off: s.writeOff, size: int64(len(record)), time: now})
+	s.bytes += int64(len(record))
+	s.writeOff += int64(len(record))
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Flush waits until the events logged so far are sent or ctx is done.
+func (s *Spool) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	idle := s.idle
+	s.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the size and age of the backlog.
+func (s *Spool) Stats() SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := SpoolStats{
+		Events:   len(s.backlog),
+		Bytes:    s.bytes,
+		Segments: len(s.segments),
+	}
+	if len(s.backlog) > 0 {
+		stats.Age = time.Since(s.backlog[0].time)
+	}
+	return stats
+}
+
+// Close stops sending the events and closes the segment files. The events not sent yet are
+// kept in dir, to be sent when the spool is opened again.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.cancel()
+	<-s.done
+	return s.closeFiles()
+}
+
+func (s *Spool) closeFiles() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	if s.writer != nil {
+		errs = append(errs, s.writer.Close())
+		s.writer = nil
+	}
+	if s.reader != nil {
+		errs = append(errs, s.reader.Close())
+		s.reader = nil
+	}
+	return errors.Join(errs...)
+}
+
+// replay sends the events of the backlog in order, until the spool is closed.
+func (s *Spool) replay() {
+	defer close(s.done)
+	for {
+		entry, ok := s.next()
+		if !ok {
+			return
+		}
+
+		record, err := s.read(entry)
+		if err == nil {
+			ctx := pangea.WithIdempotencyKey(s.ctx, record.IdempotencyKey)
+			_, err = s.client.Log(ctx, record.Input)
+			if err != nil && s.retryable(err) {
+				// Pangea may be unreachable, send the event again later.
+				if !s.sleep(s.cfg.RetryInterval) {
+					return
+				}
+				continue
+			}
+		}
+		if err != nil && s.cfg.OnError != nil {
+			s.cfg.OnError(record.Input, err)
+		}
+		if err := s.commit(entry); err != nil && s.cfg.OnError != nil {
+			s.cfg.OnError(nil, err)
+		}
+	}
+}
+
+// retryable returns whether the event failing with err may be logged by sending it again.
+func (s *Spool) retryable(err error) bool {
+	switch {
+	case s.ctx.Err() != nil:
+		// The spool is closed, the event is sent again when it is opened.
+		return true
+	case errors.Is(err, pangea.ErrRateLimited), errors.Is(err, pangea.ErrUnauthorized), errors.Is(err, pangea.ErrCircuitOpen):
+		return true
+	}
+	var unmarshalErr *pangea.UnMarshalError
+	if errors.As(err, &unmarshalErr) {
+		return isServerError(unmarshalErr.HTTPResponse)
+	}
+	var apiErr *pangea.APIError
+	if errors.As(err, &apiErr) {
+		// Without a response, the request did not reach Pangea.
+		return (apiErr.HTTPResponse == nil && apiErr.ResponseHeader == nil) || isServerError(apiErr.HTTPResponse)
+	}
+	return false
+}
+
+func isServerError(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode >= 500
+}
+
+// next returns the oldest event not sent yet, waiting for one to be logged.
+// It returns false once the spool is closed.
+func (s *Spool) next() (spoolEntry, bool) {
+	for {
+		s.mu.Lock()
+		if len(s.backlog) > 0 {
+			entry := s.backlog[0]
+			s.mu.Unlock()
+			return entry, true
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-s.ctx.Done():
+			return spoolEntry{}, false
+		}
+	}
+}
+
+func (s *Spool) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// read reads the record of entry. Its Input is nil if the record is corrupted.
+func (s *Spool) read(entry spoolEntry) (spoolRecord, error) {
+	var record spoolRecord
+	if s.reader == nil || s.readerSeg != entry.seg {
+		if s.reader != nil {
+			s.reader.Close()
+		}
+		f, err := os.Open(s.segmentPath(entry.seg))
+		if err != nil {
+			s.reader = nil
+			return record, fmt.Errorf("audit: spool: %w", err)
+		}
+		s.reader, s.readerSeg = f, entry.seg
+	}
+
+	buf := make([]byte, entry.size)
+	if _, err := s.reader.ReadAt(buf, entry.off); err != nil {
+		return record, fmt.Errorf("audit: spool: %w", err)
+	}
+	payload, ok := checkSpoolRecord(buf)
+	if !ok {
+		return record, fmt.Errorf("%w: segment %v at offset %v", ErrSpoolCorrupted, entry.seg, entry.off)
+	}
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return spoolRecord{}, fmt.Errorf("%w: segment %v at offset %v: %v", ErrSpoolCorrupted, entry.seg, entry.off, err)
+	}
+	return record, nil
+}
+
+// commit removes the event of entry from the backlog, saves the cursor and removes the
+// segment files fully sent.
+func (s *Spool) commit(entry spoolEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backlog = s.backlog[1:]
+	s.bytes -= entry.size
+	if len(s.backlog) == 0 {
+		close(s.idle)
+	}
+
+	// The cursor skips the corrupted records between the event and the next one.
+	next := entry.off + entry.size
+	if len(s.backlog) > 0 && s.backlog[0].seg == entry.seg {
+		next = s.backlog[0].off
+	}
+	cursor := fmt.Sprintf("%d %d\n", entry.seg, next)
+	if err := writeFileAtomic(filepath.Join(s.dir, spoolCursorFile), []byte(cursor)); err != nil {
+		return fmt.Errorf("audit: spool: %w", err)
+	}
+
+	var errs []error
+	for len(s.segments) > 0 && s.segments[0] < entry.seg {
+		errs = append(errs, s.removeSegment(s.segments[0]))
+	}
+	// The segment is fully sent if no more events are appended to it.
+	if len(s.backlog) == 0 && entry.seg != s.writeSeg || len(s.backlog) > 0 && s.backlog[0].seg != entry.seg {
+		errs = append(errs, s.removeSegment(entry.seg))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Spool) removeSegment(seg int64) error {
+	if s.reader != nil && s.readerSeg == seg {
+		s.reader.Close()
+		s.reader = nil
+	}
+	if len(s.segments) > 0 && s.segments[0] == seg {
+		s.segments = s.segments[1:]
+	}
+	err := os.Remove(s.segmentPath(seg))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("audit: spool: %w", err)
+	}
+	return nil
+}
+
+// rotate starts a new segment file.
+func (s *Spool) rotate() error {
+	if s.writer != nil {
+		if err := s.writer.Close(); err != nil {
+			return fmt.Errorf("audit: spool: %w", err)
+		}
+		s.writer = nil
+	}
+	seg := s.writeSeg + 1
+	f, err := os.OpenFile(s.segmentPath(seg), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("audit: spool: %w", err)
+	}
+	s.writer, s.writeSeg, s.writeOff = f, seg, 0
+	s.segments = append(s.segments, seg)
+	return nil
+}
+
+// load reads the cursor and indexes the events of the segment files not sent yet.
+func (s *Spool) load() error {
+	segments, err := s.listSegments()
+	if err != nil {
+		return err
+	}
+	cursorSeg, cursorOff, err := s.readCursor()
+	if err != nil {
+		return err
+	}
+
+	// The events are appended to a new segment, numbered after the cursor even if its
+	// segment was removed.
+	s.writeSeg = cursorSeg
+	for _, seg := range segments {
+		if seg < cursorSeg {
+			if err := os.Remove(s.segmentPath(seg)); err != nil {
+				return fmt.Errorf("audit: spool: %w", err)
+			}
+			continue
+		}
+		s.segments = append(s.segments, seg)
+		s.writeSeg = seg
+
+		off := int64(0)
+		if seg == cursorSeg {
+			off = cursorOff
+		}
+		end, err := s.index(seg, off)
+		if errors.Is(err, ErrSpoolCorrupted) {
+			// The last record was partially written, when the process stopped or the
+			// write failed, discard it.
+			err = os.Truncate(s.segmentPath(seg), end)
+		}
+		if err != nil {
+			return fmt.Errorf("audit: spool: %w", err)
+		}
+	}
+	return nil
+}
+
+// index adds the records of segment seg from offset off to the backlog, it returns the end
+// of the last valid record. A corrupted record followed by valid ones, e.g. damaged on disk,
+// is skipped and its loss is reported to OnError. A corrupted record at the end of the
+// segment was partially written, ErrSpoolCorrupted is returned for it to be discarded.
+func (s *Spool) index(seg, off int64) (int64, error) {
+	data, err := os.ReadFile(s.segmentPath(seg))
+	if err != nil {
+		return 0, fmt.Errorf("audit: spool: %w", err)
+	}
+	if off > int64(len(data)) {
+		off = int64(len(data))
+	}
+
+	for off < int64(len(data)) {
+		size, t, ok := parseSpoolRecord(data[off:])
+		if ok {
+			s.backlog = append(s.backlog, spoolEntry{seg: seg, off: off, size: size, time: t})
+			s.bytes += size
+			off += size
+			continue
+		}
+
+		// Look for the next valid record, the corrupted bytes are at the end without one.
+		next := off + 1
+		for next < int64(len(data)) {
+			if _, _, ok := parseSpoolRecord(data[next:]); ok {
+				break
+			}
+			next++
+		}
+		if next == int64(len(data)) {
+			return off, fmt.Errorf("%w: segment %v at offset %v", ErrSpoolCorrupted, seg, off)
+		}
+		if s.cfg.OnError != nil {
+			s.cfg.OnError(nil, fmt.Errorf("%w: segment %v at offset %v: %v bytes skipped", ErrSpoolCorrupted, seg, off, next-off))
+		}
+		off = next
+	}
+	return off, nil
+}
+
+// parseSpoolRecord returns the size and time of the record at the start of data, or false if
+// it is not a valid record.
+func parseSpoolRecord(data []byte) (int64, time.Time, bool) {
+	if len(data) < spoolHeaderSize {
+		return 0, time.Time{}, false
+	}
+	size := binary.BigEndian.Uint32(data)
+	if size > spoolMaxRecord || int64(size) > int64(len(data)-spoolHeaderSize) {
+		return 0, time.Time{}, false
+	}
+	record := data[:spoolHeaderSize+int(size)]
+	payload, ok := checkSpoolRecord(record)
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	var r struct {
+		Time time.Time `json:"time"`
+	}
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return 0, time.Time{}, false
+	}
+	return int64(len(record)), r.Time, true
+}
+
+func (s *Spool) listSegments() ([]int64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("audit: spool: %w", err)
+	}
+	var segments []int64
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), spoolSegmentExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		seg, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	return segments, nil
+}
+
+// readCursor returns the segment and offset of the first event not sent yet.
+func (s *Spool) readCursor() (int64, int64, error) {
+	b, err := os.ReadFile(filepath.Join(s.dir, spoolCursorFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("audit: spool: %w", err)
+	}
+	var seg, off int64
+	if _, err := fmt.Sscanf(string(b), "%d %d", &seg, &off); err != nil {
+		return 0, 0, fmt.Errorf("%w: invalid cursor %q", ErrSpoolCorrupted, b)
+	}
+	return seg, off, nil
+}
+
+func (s *Spool) segmentPath(seg int64) string {
+	return filepath.Join(s.dir, fmt.Sprintf(spoolSegmentName, seg))
+}
+
+// checkSpoolRecord returns the payload of a record if its checksum matches.
+func checkSpoolRecord(record []byte) ([]byte, bool) {
+	if len(record) < spoolHeaderSize {
+		return nil, false
+	}
+	payload := record[spoolHeaderSize:]
+	if int(binary.BigEndian.Uint32(record)) != len(payload) {
+		return nil, false
+	}
+	return payload, binary.BigEndian.Uint32(record[4:]) == crc32.Checksum(payload, spoolCRCTable)
+}
+
+// writeFileAtomic replaces the file at path with data, so it is not left partially written.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,259 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/pangea/pangeafake"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/pangeacyber/go-pangea/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// errUnreachable is the error of the requests failing to reach Pangea.
+var errUnreachable = pangea.NewAPIError(errors.New("dial tcp: connection refused"), nil, nil)
+
+// loggedMessages returns the messages of the successful Log calls of client, in order.
+func loggedMessages(client *mocks.AuditClient) []string {
+	var msgs []string
+	for _, call := range client.LogMethod.Calls() {
+		msgs = append(msgs, pangea.StringValue(call.Input.Event.Message))
+	}
+	return msgs
+}
+
+func flushSpool(t *testing.T, spool *audit.Spool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, spool.Flush(ctx))
+}
+
+func TestSpool_Sends_Events_In_Order_Once_Pangea_Recovers(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.LogMethod.ReturnError(errUnreachable).Times(3)
+	client.LogMethod.Return(&audit.LogOutput{})
+
+	spool, err := audit.OpenSpool(t.TempDir(), client, &audit.SpoolConfig{RetryInterval: time.Millisecond})
+	assert.NoError(t, err)
+	defer spool.Close()
+
+	for _, msg := range []string{"first", "second", "third"} {
+		assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent(msg)))
+	}
+	flushSpool(t, spool)
+
+	// The first event is sent until it succeeds, then the others.
+	assert.Equal(t, []string{"first", "first", "first", "first", "second", "third"}, loggedMessages(client))
+	calls := client.LogMethod.Calls()
+	firstKey, _ := pangea.IdempotencyKeyFromContext(calls[0].Ctx)
+	retryKey, _ := pangea.IdempotencyKeyFromContext(calls[3].Ctx)
+	assert.NotEmpty(t, firstKey)
+	assert.Equal(t, firstKey, retryKey)
+	assert.Equal(t, audit.SpoolStats{Segments: 1}, spool.Stats())
+	client.AssertExpectations(t)
+}
+
+func TestSpool_Sends_Events_Left_After_Restart(t *testing.T) {
+	dir := t.TempDir()
+	down := mocks.NewAuditClient()
+	down.LogMethod.ReturnError(errUnreachable)
+
+	spool, err := audit.OpenSpool(dir, down, &audit.SpoolConfig{RetryInterval: time.Hour})
+	assert.NoError(t, err)
+	for _, msg := range []string{"first", "second", "third"} {
+		assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent(msg)))
+	}
+	assert.Eventually(t, func() bool { return len(down.LogMethod.Calls()) == 1 }, 5*time.Second, time.Millisecond)
+	stats := spool.Stats()
+	assert.Equal(t, 3, stats.Events)
+	assert.Greater(t, stats.Bytes, int64(0))
+	assert.Greater(t, stats.Age, time.Duration(0))
+	assert.NoError(t, spool.Close())
+	assert.ErrorIs(t, spool.Log(context.Background(), newAsyncTestEvent("late")), audit.ErrSpoolClosed)
+
+	up := mocks.NewAuditClient()
+	up.LogMethod.Return(&audit.LogOutput{})
+	spool, err = audit.OpenSpool(dir, up, nil)
+	assert.NoError(t, err)
+	defer spool.Close()
+	assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent("fourth")))
+	flushSpool(t, spool)
+
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, loggedMessages(up))
+	downKey, _ := pangea.IdempotencyKeyFromContext(down.LogMethod.Calls()[0].Ctx)
+	upKey, _ := pangea.IdempotencyKeyFromContext(up.LogMethod.Calls()[0].Ctx)
+	assert.Equal(t, downKey, upKey)
+	assert.Equal(t, 0, spool.Stats().Events)
+}
+
+func TestSpool_Reports_And_Removes_Rejected_Events(t *testing.T) {
+	srv := pangeafake.NewServer()
+	defer srv.Close()
+	client, err := audit.New(srv.Config())
+	assert.NoError(t, err)
+
+	var mu sync.Mutex
+	var rejected []error
+	spool, err := audit.OpenSpool(t.TempDir(), client, &audit.SpoolConfig{
+		OnError: func(input *audit.LogInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			rejected = append(rejected, err)
+		},
+	})
+	assert.NoError(t, err)
+	defer spool.Close()
+
+	// The event without message is rejected by the service.
+	assert.NoError(t, spool.Log(context.Background(), &audit.LogInput{Event: &audit.Event{Actor: pangea.String("actor")}}))
+	assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent("accepted")))
+	flushSpool(t, spool)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, rejected, 1)
+	assert.ErrorIs(t, rejected[0], pangea.ErrValidation)
+	assert.Len(t, srv.Events(), 1)
+}
+
+func TestSpool_Retries_Only_Transient_Errors(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.LogMethod.ReturnError(&pangea.APIError{HTTPResponse: &http.Response{StatusCode: http.StatusServiceUnavailable}}).Once()
+	client.LogMethod.ReturnError(pangea.ErrRateLimited).Once()
+	forbidden := &pangea.APIError{HTTPResponse: &http.Response{StatusCode: http.StatusForbidden}}
+	client.LogMethod.ReturnError(forbidden).Once()
+	signErr := errors.New("audit: cannot sign event")
+	client.LogMethod.ReturnError(signErr).Once()
+	malformed := pangea.NewUnMarshalError(errors.New("unexpected end of JSON input"), nil, &http.Response{StatusCode: http.StatusOK}, nil)
+	client.LogMethod.ReturnError(malformed).Once()
+	client.LogMethod.Return(&audit.LogOutput{})
+
+	var mu sync.Mutex
+	var failed []error
+	spool, err := audit.OpenSpool(t.TempDir(), client, &audit.SpoolConfig{
+		RetryInterval: time.Millisecond,
+		OnError: func(input *audit.LogInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, err)
+		},
+	})
+	assert.NoError(t, err)
+	defer spool.Close()
+
+	for _, msg := range []string{"first", "second", "third", "fourth"} {
+		assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent(msg)))
+	}
+	flushSpool(t, spool)
+
+	// The first event is sent again after the 503 and 429, the permanent errors are reported.
+	assert.Equal(t, []string{"first", "first", "first", "second", "third", "fourth"}, loggedMessages(client))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []error{forbidden, signErr, malformed}, failed)
+	assert.Equal(t, audit.SpoolStats{Segments: 1}, spool.Stats())
+}
+
+func TestSpool_Discards_Partially_Written_Record(t *testing.T) {
+	dir := t.TempDir()
+	down := mocks.NewAuditClient()
+	down.LogMethod.ReturnError(errUnreachable)
+	spool, err := audit.OpenSpool(dir, down, &audit.SpoolConfig{RetryInterval: time.Hour})
+	assert.NoError(t, err)
+	assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent("first")))
+	assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent("second")))
+	assert.NoError(t, spool.Close())
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	assert.NoError(t, err)
+	assert.Len(t, segments, 1)
+	f, err := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 42, 42})
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	up := mocks.NewAuditClient()
+	up.LogMethod.Return(&audit.LogOutput{})
+	spool, err = audit.OpenSpool(dir, up, nil)
+	assert.NoError(t, err)
+	defer spool.Close()
+	assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent("third")))
+	flushSpool(t, spool)
+
+	assert.Equal(t, []string{"first", "second", "third"}, loggedMessages(up))
+}
+
+func TestSpool_Skips_And_Reports_Corrupted_Record(t *testing.T) {
+	dir := t.TempDir()
+	down := mocks.NewAuditClient()
+	down.LogMethod.ReturnError(errUnreachable)
+	spool, err := audit.OpenSpool(dir, down, &audit.SpoolConfig{RetryInterval: time.Hour})
+	assert.NoError(t, err)
+	for _, msg := range []string{"first", "second", "third"} {
+		assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent(msg)))
+	}
+	assert.NoError(t, spool.Close())
+
+	// Damage the payload of the second record, in the middle of the segment.
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	assert.NoError(t, err)
+	assert.Len(t, segments, 1)
+	data, err := os.ReadFile(segments[0])
+	assert.NoError(t, err)
+	i := bytes.Index(data, []byte("second"))
+	assert.Greater(t, i, 0)
+	data[i] = 'S'
+	assert.NoError(t, os.WriteFile(segments[0], data, 0o600))
+
+	var mu sync.Mutex
+	var reported []error
+	up := mocks.NewAuditClient()
+	up.LogMethod.Return(&audit.LogOutput{})
+	spool, err = audit.OpenSpool(dir, up, &audit.SpoolConfig{
+		OnError: func(input *audit.LogInput, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Nil(t, input)
+			reported = append(reported, err)
+		},
+	})
+	assert.NoError(t, err)
+	defer spool.Close()
+	flushSpool(t, spool)
+
+	assert.Equal(t, []string{"first", "third"}, loggedMessages(up))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, reported, 1)
+	assert.ErrorIs(t, reported[0], audit.ErrSpoolCorrupted)
+}
+
+func TestSpool_Removes_Sent_Segments(t *testing.T) {
+	dir := t.TempDir()
+	client := mocks.NewAuditClient()
+	client.LogMethod.Return(&audit.LogOutput{})
+	spool, err := audit.OpenSpool(dir, client, &audit.SpoolConfig{SegmentSize: 1})
+	assert.NoError(t, err)
+	defer spool.Close()
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, spool.Log(context.Background(), newAsyncTestEvent("message")))
+	}
+	flushSpool(t, spool)
+
+	assert.Len(t, client.LogMethod.Calls(), 5)
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	assert.NoError(t, err)
+	assert.Len(t, segments, 1)
+	assert.Equal(t, 1, spool.Stats().Segments)
+}
@@ -144,8 +144,11 @@ func TestIPCheck(t *testing.T) {
 
 func TestCheckError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := embargo.New(cfg)
-		_, err := client.ISOCheck(context.Background(), nil)
+		client, err := embargo.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.ISOCheck(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Embargo.Check", f)
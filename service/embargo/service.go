@@ -19,6 +19,9 @@ func New(cfg *pangea.Config, opts ...Option) (*Embargo, error) {
 	cli := &Embargo{
 		Client: pangea.NewClient("embargo", cfg),
 	}
+	if err := cli.Client.Err(); err != nil {
+		return nil, err
+	}
 	for _, opt := range opts {
 		err := opt(cli)
 		if err != nil {
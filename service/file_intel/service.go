@@ -18,6 +18,9 @@ func New(cfg *pangea.Config, opts ...Option) (*FileIntel, error) {
 	cli := &FileIntel{
 		Client: pangea.NewClient("file-intel", cfg),
 	}
+	if err := cli.Client.Err(); err != nil {
+		return nil, err
+	}
 	for _, opt := range opts {
 		err := opt(cli)
 		if err != nil {
@@ -18,6 +18,9 @@ func New(cfg *pangea.Config, opts ...Option) (*IpIntel, error) {
 	cli := &IpIntel{
 		Client: pangea.NewClient("ip-intel", cfg),
 	}
+	if err := cli.Client.Err(); err != nil {
+		return nil, err
+	}
 	for _, opt := range opts {
 		err := opt(cli)
 		if err != nil {
@@ -0,0 +1,207 @@
+// Package mocks provides programmable fakes of the Client interface of every service,
+// to test the code calling Pangea without sending requests.
+//
+// Each fake has a Method field per method of the interface, to set the responses, inject
+// errors and inspect the calls:
+//
+//	auditcli := mocks.NewAuditClient()
+//	auditcli.LogMethod.Return(&audit.LogOutput{Hash: pangea.String("some-hash")})
+//	auditcli.SearchMethod.On(func(in *audit.SearchInput) bool {
+//		return pangea.StringValue(in.Query) == "actor:root"
+//	}).ReturnError(pangea.ErrRateLimited).Once()
+//
+//	useTheClient(auditcli)
+//
+//	auditcli.AssertExpectations(t)
+//	assert.Len(t, auditcli.LogMethod.Calls(), 1)
+//
+// The fakes are generated from the service interfaces by dev/mockgen, run go generate
+// after changing an interface.
+package mocks
+
+//go:generate go run ../../dev/mockgen -services .. -out .
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+)
+
+// ErrUnexpectedCall is returned by the calls matching no expectation.
+var ErrUnexpectedCall = errors.New("mocks: unexpected call")
+
+// Call is a call recorded by a Method.
+type Call[I any] struct {
+	Ctx   context.Context
+	Input *I
+}
+
+// Method is a programmable fake of a service method taking an input of type I and
+// returning a result of type O. The zero value is ready to use and fails every call
+// with ErrUnexpectedCall. It is safe for concurrent use.
+type Method[I, O any] struct {
+	mu           sync.Mutex
+	name         string
+	expectations []*Expectation[I, O]
+	calls        []Call[I]
+}
+
+// Expectation is the response of a Method to the calls it matches.
+type Expectation[I, O any] struct {
+	match func(*I) bool
+	do    func(context.Context, *I) (*pangea.PangeaResponse[O], error)
+	times int
+	calls int
+}
+
+// On adds an expectation for the calls with an input matched by match.
+// The expectations are matched in the order they were added.
+func (m *Method[I, O]) On(match func(input *I) bool) *Expectation[I, O] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &Expectation[I, O]{
+		match: match,
+		do: func(context.Context, *I) (*pangea.PangeaResponse[O], error) {
+			return NewResponse(new(O)), nil
+		},
+	}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// OnInput adds an expectation for the calls with an input deeply equal to input.
+func (m *Method[I, O]) OnInput(input *I) *Expectation[I, O] {
+	return m.On(func(in *I) bool {
+		return reflect.DeepEqual(in, input)
+	})
+}
+
+// OnAny adds an expectation for every call.
+func (m *Method[I, O]) OnAny() *Expectation[I, O] {
+	return m.On(func(*I) bool { return true })
+}
+
+// Return is a shortcut for OnAny().Return(result).
+func (m *Method[I, O]) Return(result *O) *Expectation[I, O] {
+	return m.OnAny().Return(result)
+}
+
+// ReturnError is a shortcut for OnAny().ReturnError(err).
+func (m *Method[I, O]) ReturnError(err error) *Expectation[I, O] {
+	return m.OnAny().ReturnError(err)
+}
+
+// Call records the call and returns the response of the first matching expectation.
+// It is called by the methods of the generated fakes.
+func (m *Method[I, O]) Call(ctx context.Context, input *I) (*pangea.PangeaResponse[O], error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, Call[I]{Ctx: ctx, Input: input})
+	var do func(context.Context, *I) (*pangea.PangeaResponse[O], error)
+	for _, e := range m.expectations {
+		if (e.times == 0 || e.calls < e.times) && e.match(input) {
+			e.calls++
+			do = e.do
+			break
+		}
+	}
+	name := m.name
+	m.mu.Unlock()
+	if name == "" {
+		name = "method"
+	}
+
+	if do == nil {
+		return nil, fmt.Errorf("%w to %v with %v", ErrUnexpectedCall, name, pangea.Stringify(input))
+	}
+	return do(ctx, input)
+}
+
+// Calls returns the calls made so far.
+func (m *Method[I, O]) Calls() []Call[I] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call[I](nil), m.calls...)
+}
+
+// Reset removes the expectations and the recorded calls.
+func (m *Method[I, O]) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = nil
+	m.calls = nil
+}
+
+// AssertExpectations fails the test if an expectation limited with Times was not
+// called as many times.
+func (m *Method[I, O]) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ok := true
+	for i, e := range m.expectations {
+		if e.times > 0 && e.calls != e.times {
+			t.Errorf("mocks: expectation %v of %v called %v time(s), expected %v", i, m.name, e.calls, e.times)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Return makes the matched calls succeed with result.
+func (e *Expectation[I, O]) Return(result *O) *Expectation[I, O] {
+	return e.ReturnResponse(NewResponse(result))
+}
+
+// ReturnResponse makes the matched calls return resp, e.g. to set its response header.
+func (e *Expectation[I, O]) ReturnResponse(resp *pangea.PangeaResponse[O]) *Expectation[I, O] {
+	e.do = func(context.Context, *I) (*pangea.PangeaResponse[O], error) {
+		return resp, nil
+	}
+	return e
+}
+
+// ReturnError makes the matched calls fail with err, e.g. pangea.ErrRateLimited.
+func (e *Expectation[I, O]) ReturnError(err error) *Expectation[I, O] {
+	e.do = func(context.Context, *I) (*pangea.PangeaResponse[O], error) {
+		return nil, err
+	}
+	return e
+}
+
+// Do makes the matched calls return the result of f.
+func (e *Expectation[I, O]) Do(f func(ctx context.Context, input *I) (*pangea.PangeaResponse[O], error)) *Expectation[I, O] {
+	e.do = f
+	return e
+}
+
+// Times limits the expectation to n calls, later calls are matched by the next expectations.
+// AssertExpectations checks it was called n times.
+func (e *Expectation[I, O]) Times(n int) *Expectation[I, O] {
+	e.times = n
+	return e
+}
+
+// Once is a shortcut for Times(1).
+func (e *Expectation[I, O]) Once() *Expectation[I, O] {
+	return e.Times(1)
+}
+
+// NewResponse returns a successful response with result.
+func NewResponse[O any](result *O) *pangea.PangeaResponse[O] {
+	return &pangea.PangeaResponse[O]{
+		Response: pangea.Response{
+			ResponseHeader: pangea.ResponseHeader{
+				RequestID:  pangea.String("prq_mock"),
+				Status:     pangea.String("Success"),
+				StatusCode: pangea.Int(200),
+				Summary:    pangea.String("Success"),
+			},
+		},
+		Result: result,
+	}
+}
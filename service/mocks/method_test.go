@@ -0,0 +1,69 @@
+package mocks_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pangeacyber/go-pangea/pangea"
+	"github.com/pangeacyber/go-pangea/service/audit"
+	"github.com/pangeacyber/go-pangea/service/ip_intel"
+	"github.com/pangeacyber/go-pangea/service/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMethod_Returns_Result_Of_First_Matching_Expectation(t *testing.T) {
+	client := mocks.NewIpIntelClient()
+	client.LookupMethod.On(func(in *ip_intel.IpLookupInput) bool {
+		return in.Ip == "93.231.182.110"
+	}).Return(&ip_intel.IpLookupOutput{Data: ip_intel.LookupData{Verdict: "malicious"}})
+	client.LookupMethod.Return(&ip_intel.IpLookupOutput{Data: ip_intel.LookupData{Verdict: "benign"}})
+
+	var c ip_intel.Client = client
+	resp, err := c.Lookup(context.Background(), &ip_intel.IpLookupInput{Ip: "93.231.182.110"})
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", resp.Result.Data.Verdict)
+	assert.Equal(t, "Success", pangea.StringValue(resp.Status))
+
+	resp, err = c.Lookup(context.Background(), &ip_intel.IpLookupInput{Ip: "1.1.1.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "benign", resp.Result.Data.Verdict)
+
+	calls := client.LookupMethod.Calls()
+	assert.Len(t, calls, 2)
+	assert.Equal(t, "1.1.1.1", calls[1].Input.Ip)
+}
+
+func TestMethod_Injects_Errors_And_Checks_Times(t *testing.T) {
+	client := mocks.NewAuditClient()
+	input := &audit.LogInput{Event: &audit.Event{Message: pangea.String("hello")}}
+	client.LogMethod.OnInput(input).ReturnError(pangea.ErrRateLimited).Once()
+	client.LogMethod.OnInput(input).Return(&audit.LogOutput{Hash: pangea.String("some-hash")})
+
+	_, err := client.Log(context.Background(), &audit.LogInput{Event: &audit.Event{Message: pangea.String("hello")}})
+	assert.ErrorIs(t, err, pangea.ErrRateLimited)
+
+	resp, err := client.Log(context.Background(), input)
+	assert.NoError(t, err)
+	assert.Equal(t, "some-hash", pangea.StringValue(resp.Result.Hash))
+	assert.True(t, client.AssertExpectations(t))
+
+	_, err = client.Log(context.Background(), &audit.LogInput{Event: &audit.Event{Message: pangea.String("other")}})
+	assert.ErrorIs(t, err, mocks.ErrUnexpectedCall)
+	assert.ErrorContains(t, err, "audit.Client.Log")
+}
+
+func TestMethod_AssertExpectations_Fails_When_Not_Called(t *testing.T) {
+	client := mocks.NewAuditClient()
+	client.RootMethod.OnAny().Do(func(ctx context.Context, in *audit.RootInput) (*pangea.PangeaResponse[audit.RootOutput], error) {
+		return mocks.NewResponse(&audit.RootOutput{Data: &audit.Root{Size: in.TreeSize}}), nil
+	})
+	client.SearchMethod.OnAny().Times(2)
+
+	resp, err := client.Root(context.Background(), &audit.RootInput{TreeSize: pangea.Int(3)})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, pangea.IntValue(resp.Result.Data.Size))
+
+	mockT := &testing.T{}
+	assert.False(t, client.AssertExpectations(mockT))
+	assert.True(t, mockT.Failed())
+}
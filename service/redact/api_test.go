@@ -102,8 +102,11 @@ func TestRedactStructured(t *testing.T) {
 
 func TestRedactError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := redact.New(cfg)
-		_, err := client.Redact(context.Background(), nil)
+		client, err := redact.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.Redact(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Redact.Redact", f)
@@ -111,8 +114,11 @@ func TestRedactError(t *testing.T) {
 
 func TestRedactStructuredError(t *testing.T) {
 	f := func(cfg *pangea.Config) error {
-		client, _ := redact.New(cfg)
-		_, err := client.RedactStructured(context.Background(), nil)
+		client, err := redact.New(cfg)
+		if err != nil {
+			return err
+		}
+		_, err = client.RedactStructured(context.Background(), nil)
 		return err
 	}
 	pangeatesting.TestNewRequestAndDoFailure(t, "Redact.RedactStructured", f)
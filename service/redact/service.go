@@ -7,7 +7,7 @@ import (
 )
 
 type Client interface {
-	RedactText(ctx context.Context, input *TextInput) (*pangea.PangeaResponse[TextOutput], error)
+	Redact(ctx context.Context, input *TextInput) (*pangea.PangeaResponse[TextOutput], error)
 	RedactStructured(ctx context.Context, input *StructuredInput) (*pangea.PangeaResponse[StructuredOutput], error)
 }
 
@@ -15,10 +15,16 @@ type Redact struct {
 	*pangea.Client
 }
 
+// The method of the interface was named RedactText, which Redact does not implement.
+var _ Client = (*Redact)(nil)
+
 func New(cfg *pangea.Config, opts ...Option) (*Redact, error) {
 	cli := &Redact{
 		Client: pangea.NewClient("redact", cfg),
 	}
+	if err := cli.Client.Err(); err != nil {
+		return nil, err
+	}
 	for _, opt := range opts {
 		err := opt(cli)
 		if err != nil {